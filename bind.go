@@ -0,0 +1,277 @@
+package r2router
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Bind fills the struct dst points to from the request using field tags.
+// path binds a param, query a query value and header a request header,
+// such as
+//
+//	type UserRequest struct {
+//		ID     int       `path:"id"`
+//		Page   int       `query:"page" default:"1"`
+//		Tenant string    `header:"X-Tenant,required"`
+//		Tags   []string  `query:"tag"`
+//		Body   UserInput `json:"body"`
+//	}
+//
+// A body with a JSON content type is decoded into dst first, so
+// fields with json tags are set as encoding/json does. The default tag
+// is used when a value is absent, unless the body has the field, and
+// the required option makes an absent value an error even if the body
+// has the field. Fields can be strings, bools, numbers,
+// encoding.TextUnmarshaler such as time.Time, pointers to those and,
+// for query and header, slices of those. Anonymous struct fields
+// are bound too. It returns a BindError listing every field which
+// could not be bound, an error if dst is not a pointer to a struct
+func Bind(req *http.Request, params Params, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind needs a pointer to a struct, got %T", dst)
+	}
+	if params == nil {
+		params = &params_{}
+	}
+	var errs BindError
+	body, err := bindBody(req, dst)
+	if err != nil {
+		errs = append(errs, &FieldError{Source: "body", Err: fmt.Errorf("%w: %v", ErrInvalidParam, err)})
+	}
+	b := &binder{req: req, params: params}
+	b.bindStruct(v.Elem(), body, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// bindBody decodes a JSON body into dst and returns
+// the members of the body object, see bodyMembers
+func bindBody(req *http.Request, dst interface{}) (map[string]json.RawMessage, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.ContentLength == 0 {
+		return nil, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return nil, nil
+	}
+	var raw json.RawMessage
+	if err := json.NewDecoder(req.Body).Decode(&raw); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return bodyMembers(raw), err
+	}
+	return bodyMembers(raw), nil
+}
+
+// bodyMembers returns the members of a JSON object by lowercased
+// name, as encoding/json matches them to fields regardless of case.
+// It is nil if raw is not an object
+func bodyMembers(raw json.RawMessage) map[string]json.RawMessage {
+	var members map[string]json.RawMessage
+	if json.Unmarshal(raw, &members) != nil {
+		return nil
+	}
+	lowered := make(map[string]json.RawMessage, len(members))
+	for name, value := range members {
+		lowered[strings.ToLower(name)] = value
+	}
+	return lowered
+}
+
+// jsonName returns the lowercased name encoding/json uses
+// for the field, empty if it is not decoded
+func jsonName(field reflect.StructField) string {
+	name := field.Tag.Get("json")
+	if i := strings.IndexByte(name, ','); i != -1 {
+		name = name[:i]
+	}
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		if field.Anonymous {
+			return ""
+		}
+		name = field.Name
+	}
+	return strings.ToLower(name)
+}
+
+// binder holds the request values for Bind
+type binder struct {
+	req    *http.Request
+	params Params
+	query  map[string][]string
+}
+
+// bindStruct binds the fields of v, body has the members of the
+// JSON object decoded into v so values it has are not replaced
+func (b *binder) bindStruct(v reflect.Value, body map[string]json.RawMessage, errs *BindError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			// fields are promoted unless the json tag names it
+			embedded := body
+			if name := jsonName(field); name != "" {
+				embedded = bodyMembers(body[name])
+			}
+			b.bindStruct(v.Field(i), embedded, errs)
+			continue
+		}
+		if field.PkgPath != "" {
+			// not exported
+			continue
+		}
+		for _, source := range []string{"path", "query", "header"} {
+			tag, ok := field.Tag.Lookup(source)
+			if !ok {
+				continue
+			}
+			name := jsonName(field)
+			_, inBody := body[name]
+			inBody = inBody && name != ""
+			if err := b.bindField(v.Field(i), field, source, tag, inBody); err != nil {
+				*errs = append(*errs, err)
+			}
+			break
+		}
+	}
+}
+
+// bindField sets the field from the source value named in the tag,
+// inBody is set if the field was decoded from the body
+func (b *binder) bindField(v reflect.Value, field reflect.StructField, source, tag string, inBody bool) *FieldError {
+	name, opts := tag, ""
+	if i := strings.IndexByte(tag, ','); i != -1 {
+		name, opts = tag[:i], tag[i+1:]
+	}
+	if name == "" {
+		name = field.Name
+	}
+	fe := &FieldError{Field: field.Name, Source: source, Name: name}
+	values := b.values(source, name)
+	if len(values) == 0 {
+		def, hasDefault := field.Tag.Lookup("default")
+		switch {
+		case hasDefault && !inBody:
+			values = []string{def}
+		case !hasDefault && hasOption(opts, "required"):
+			// the body can not stand in for the source
+			fe.Err = ErrMissingParam
+			return fe
+		default:
+			return nil
+		}
+	}
+	if v.Kind() == reflect.Slice && !reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(s.Index(i), value); err != nil {
+				fe.Value, fe.Err = value, err
+				return fe
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	if err := setValue(v, values[0]); err != nil {
+		fe.Value, fe.Err = values[0], err
+		return fe
+	}
+	return nil
+}
+
+// hasOption reports if opt is one of the comma separated options
+func hasOption(opts, opt string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// values returns the values of name from the source
+func (b *binder) values(source, name string) []string {
+	switch source {
+	case "path":
+		if b.params.Has(name) {
+			return []string{b.params.Get(name)}
+		}
+	case "query":
+		if b.query == nil {
+			b.query = b.req.URL.Query()
+		}
+		return b.query[name]
+	case "header":
+		return b.req.Header.Values(name)
+	}
+	return nil
+}
+
+// setValue converts the value to the type of v and sets it
+func setValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Ptr {
+		p := reflect.New(v.Type().Elem())
+		if err := setValue(p.Elem(), value); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+			return invalidValue(value, v.Type().String())
+		}
+		return nil
+	}
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(value); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(value, 10, v.Type().Bits()); err == nil {
+			v.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(value, 10, v.Type().Bits()); err == nil {
+			v.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(value, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	default:
+		err = errors.New("unsupported type")
+	}
+	if err != nil {
+		return invalidValue(value, v.Type().String())
+	}
+	return nil
+}
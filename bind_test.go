@@ -0,0 +1,160 @@
+package r2router
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindPaging struct {
+	Page  int `query:"page" default:"1"`
+	Limit int `query:"limit" default:"20"`
+}
+
+type bindInput struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+type bindRequest struct {
+	bindPaging
+	ID      int64      `path:"id"`
+	Tenant  string     `header:"X-Tenant,required"`
+	Tags    []string   `query:"tag"`
+	Active  *bool      `query:"active"`
+	Since   time.Time  `query:"since"`
+	Ratio   float64    `query:"ratio"`
+	Input   *bindInput `json:"input"`
+	ignored string     `query:"ignored"`
+}
+
+func TestBind(t *testing.T) {
+	req, _ := http.NewRequest("POST", "/users/42?tag=a&tag=b&active=true&since=2015-05-01T10:00:00Z&limit=50&ignored=x", strings.NewReader(`{"input": {"name": "Tester", "age": 20}}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Tenant", "acme")
+	p := &params_{}
+	p.push("id", "42")
+
+	dst := bindRequest{}
+	assert.Nil(t, Bind(req, p, &dst))
+	assert.Equal(t, dst.ID, int64(42))
+	assert.Equal(t, dst.Tenant, "acme")
+	assert.Equal(t, dst.Tags, []string{"a", "b"})
+	assert.True(t, *dst.Active)
+	assert.Equal(t, dst.Since, time.Date(2015, 5, 1, 10, 0, 0, 0, time.UTC))
+	assert.Equal(t, dst.Ratio, float64(0))
+	assert.Equal(t, dst.Page, 1)
+	assert.Equal(t, dst.Limit, 50)
+	assert.Equal(t, dst.Input, &bindInput{Name: "Tester", Age: 20})
+	assert.Equal(t, dst.ignored, "")
+
+	// no body and optional values absent
+	req, _ = http.NewRequest("GET", "/users/42", nil)
+	req.Header.Set("X-Tenant", "acme")
+	dst = bindRequest{}
+	assert.Nil(t, Bind(req, p, &dst))
+	assert.Nil(t, dst.Active)
+	assert.Nil(t, dst.Input)
+	assert.Equal(t, dst.Page, 1)
+}
+
+func TestBindBodyAndDefaults(t *testing.T) {
+	var dst struct {
+		bindPaging
+		Tenant string `header:"X-Tenant,omitempty,required"`
+	}
+	// Page is in the body, Limit is absent from both so the default
+	// replaces the value set before, the body can not stand in for
+	// the required header
+	req, _ := http.NewRequest("POST", "/users", strings.NewReader(`{"Page": 0, "Tenant": "evil"}`))
+	req.Header.Set("Content-Type", "application/json")
+	dst.Page, dst.Limit = 3, 5
+	err := Bind(req, nil, &dst)
+	assert.True(t, errors.Is(err, ErrMissingParam))
+	assert.Equal(t, err.Error(), "header X-Tenant: missing param")
+	assert.Equal(t, dst.Page, 0)
+	assert.Equal(t, dst.Limit, 20)
+
+	// required is found among other options and is not
+	// skipped for a value set before
+	req, _ = http.NewRequest("GET", "/users", nil)
+	dst.Tenant = "acme"
+	err = Bind(req, nil, &dst)
+	assert.True(t, errors.Is(err, ErrMissingParam))
+
+	req, _ = http.NewRequest("POST", "/users?limit=50", strings.NewReader(`{"page": 7}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant", "acme")
+	assert.Nil(t, Bind(req, nil, &dst))
+	assert.Equal(t, dst.Page, 7)
+	assert.Equal(t, dst.Limit, 50)
+	assert.Equal(t, dst.Tenant, "acme")
+
+	// fields of an embedded struct with a json name are nested
+	var nested struct {
+		bindPaging `json:"paging"`
+	}
+	req, _ = http.NewRequest("POST", "/users", strings.NewReader(`{"paging": {"limit": 0}, "page": 9}`))
+	req.Header.Set("Content-Type", "application/json")
+	assert.Nil(t, Bind(req, nil, &nested))
+	assert.Equal(t, nested.Page, 1)
+	assert.Equal(t, nested.Limit, 0)
+}
+
+func TestBindErrors(t *testing.T) {
+	req, _ := http.NewRequest("POST", "/users/abc?page=x&active=maybe&tag=a", strings.NewReader(`{"input": 1}`))
+	req.Header.Set("Content-Type", "application/json")
+	p := &params_{}
+	p.push("id", "abc")
+
+	dst := bindRequest{}
+	err := Bind(req, p, &dst)
+	berr, ok := err.(BindError)
+	assert.True(t, ok)
+	assert.Equal(t, len(berr), 5)
+	assert.Equal(t, berr[0].Source, "body")
+	assert.Equal(t, berr[1].Field, "Page")
+	assert.Equal(t, berr[1].Value, "x")
+	assert.Equal(t, berr[1].Error(), `query page: invalid param: "x" is not a valid int`)
+	assert.Equal(t, berr[2].Error(), `path id: invalid param: "abc" is not a valid int64`)
+	assert.Equal(t, berr[3].Error(), "header X-Tenant: missing param")
+	assert.Equal(t, berr[4].Error(), `query active: invalid param: "maybe" is not a valid bool`)
+	assert.True(t, errors.Is(err, ErrMissingParam))
+	assert.True(t, errors.Is(err, ErrInvalidParam))
+	assert.True(t, strings.Contains(err.Error(), `; query page: invalid param: "x" is not a valid int; `))
+	var ferr *FieldError
+	assert.True(t, errors.As(err, &ferr))
+	assert.Equal(t, ferr.Source, "body")
+
+	assert.NotNil(t, Bind(req, p, dst))
+	assert.NotNil(t, Bind(req, p, nil))
+	var unsupported struct {
+		Values map[string]string `query:"tag"`
+	}
+	err = Bind(req, p, &unsupported)
+	assert.True(t, errors.Is(err, ErrInvalidParam))
+}
+
+func TestBindHandler(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		var dst struct {
+			ID   int `path:"id"`
+			Page int `query:"page" default:"1"`
+		}
+		if HandleParamError(w, Bind(r, p, &dst)) {
+			return
+		}
+		w.Write([]byte(strconv.Itoa(dst.ID) + ":" + strconv.Itoa(dst.Page)))
+	})
+
+	assert.Equal(t, serveHost(router, "GET", "", "/users/1?page=2").Body.String(), "1:2")
+	assert.Equal(t, serveHost(router, "GET", "", "/users/1").Body.String(), "1:1")
+	res := serveHost(router, "GET", "", "/users/a?page=b")
+	assert.Equal(t, res.Code, http.StatusBadRequest)
+	assert.Equal(t, res.Body.String(), "path id: invalid param: \"a\" is not a valid int; query page: invalid param: \"b\" is not a valid int\n")
+}
@@ -0,0 +1,127 @@
+package r2router
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Constraint reports whether a path token is acceptable
+// as value for a named parameter
+type Constraint func(token string) bool
+
+var constraints = map[string]Constraint{
+	"int":   isInt,
+	"uint":  isUint,
+	"uuid":  isUUID,
+	"alpha": isAlpha,
+	"alnum": isAlnum,
+	"hex":   isHex,
+}
+
+// RegisterConstraint makes a named constraint available for routes,
+// such as /orders/:oid<uuid>. Built in are int, uint, uuid, alpha, alnum and hex.
+// It should be called before adding routes using it and is not safe
+// for concurrent use
+func RegisterConstraint(name string, constraint Constraint) {
+	if name == "" || constraint == nil {
+		panic("Constraint name and function can not be empty")
+	}
+	constraints[name] = constraint
+}
+
+// compileConstraint returns the matcher for a param constraint,
+// either a regular expression in parentheses, (\d+),
+// or a constraint type in angle brackets, <uuid>
+func compileConstraint(constraint string) (Constraint, error) {
+	switch {
+	case len(constraint) > 2 && constraint[0] == '(' && constraint[len(constraint)-1] == ')':
+		re, err := regexp.Compile("^(?:" + constraint[1:len(constraint)-1] + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid param constraint %s: %v", ErrInvalidRoute, constraint, err)
+		}
+		return re.MatchString, nil
+	case len(constraint) > 2 && constraint[0] == '<' && constraint[len(constraint)-1] == '>':
+		if matcher, exists := constraints[constraint[1:len(constraint)-1]]; exists {
+			return matcher, nil
+		}
+		return nil, fmt.Errorf("%w: unknown param type %s", ErrInvalidRoute, constraint)
+	}
+	return nil, fmt.Errorf("%w: invalid param constraint %s", ErrInvalidRoute, constraint)
+}
+
+func isUint(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isInt(s string) bool {
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	return isUint(s)
+}
+
+func isAlpha(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i] | 0x20
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isAlpha(s[i:i+1]) && !isUint(s[i:i+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i] | 0x20
+		if (c < 'a' || c > 'f') && (s[i] < '0' || s[i] > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID accepts the canonical 8-4-4-4-12 hex form
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(s[i : i+1]) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package r2router
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func mustCompileConstraint(constraint string) Constraint {
+	matcher, err := compileConstraint(constraint)
+	if err != nil {
+		panic(err)
+	}
+	return matcher
+}
+
+func TestCompileConstraint(t *testing.T) {
+	matcher := mustCompileConstraint(`(\d+)`)
+	assert.True(t, matcher("123"))
+	assert.False(t, matcher("12a"))
+
+	matcher = mustCompileConstraint("<uuid>")
+	assert.True(t, matcher("0e8b4f0a-9a3c-4c5d-8f7e-1a2b3c4d5e6f"))
+	assert.False(t, matcher("0e8b4f0a9a3c4c5d8f7e1a2b3c4d5e6f"))
+}
+
+func TestCompileConstraintBroken(t *testing.T) {
+	assert.Panics(t, func() {
+		mustCompileConstraint(`(\d+`)
+	})
+	assert.Panics(t, func() {
+		mustCompileConstraint("()")
+	})
+	assert.Panics(t, func() {
+		mustCompileConstraint("<unknown>")
+	})
+	assert.Panics(t, func() {
+		mustCompileConstraint("([)")
+	})
+}
+
+func TestBuiltinConstraints(t *testing.T) {
+	assert.True(t, isInt("-42"))
+	assert.True(t, isInt("42"))
+	assert.False(t, isInt("-"))
+	assert.False(t, isInt("4.2"))
+	assert.True(t, isUint("42"))
+	assert.False(t, isUint("-42"))
+	assert.False(t, isUint(""))
+	assert.True(t, isAlpha("abcXYZ"))
+	assert.False(t, isAlpha("abc1"))
+	assert.True(t, isAlnum("abc1"))
+	assert.False(t, isAlnum("abc-1"))
+	assert.True(t, isHex("00ffAB"))
+	assert.False(t, isHex("00fg"))
+	assert.True(t, isUUID("0E8B4F0A-9A3C-4C5D-8F7E-1A2B3C4D5E6F"))
+	assert.False(t, isUUID("0e8b4f0a-9a3c-4c5d-8f7e-1a2b3c4d5e6g"))
+}
+
+func TestRegisterConstraint(t *testing.T) {
+	RegisterConstraint("lang", func(s string) bool {
+		return s == "en" || s == "sv"
+	})
+	defer delete(constraints, "lang")
+	matcher := mustCompileConstraint("<lang>")
+	assert.True(t, matcher("sv"))
+	assert.False(t, matcher("de"))
+
+	assert.Panics(t, func() {
+		RegisterConstraint("", nil)
+	})
+}
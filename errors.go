@@ -0,0 +1,145 @@
+package r2router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrDuplicateRoute is returned when there is already
+	// a handler for the route
+	ErrDuplicateRoute = errors.New("duplicate route")
+	// ErrParamConflict is returned when a param, or catch-all,
+	// is at the same position as a registered one with the same
+	// constraint but a different name, optional or default value
+	ErrParamConflict = errors.New("param conflict")
+	// ErrInvalidRoute is returned for a malformed route pattern,
+	// such as an empty segment, param name or constraint
+	ErrInvalidRoute = errors.New("invalid route")
+	// ErrDuplicateRouteName is returned by RouteManager.Register
+	// when the name is already used
+	ErrDuplicateRouteName = errors.New("duplicate route name")
+	// ErrMissingParam is returned by the typed Params getters
+	// when there is no param with the name
+	ErrMissingParam = errors.New("missing param")
+	// ErrInvalidParam is returned by the typed Params getters
+	// when the value can not be converted
+	ErrInvalidParam = errors.New("invalid param")
+)
+
+// RouteError is returned when a route can not be registered.
+// Err is one of the errors above, possibly wrapped with details,
+// so errors.Is can be used for checking the reason
+type RouteError struct {
+	// Method is empty for RouteManager
+	Method string
+	// Pattern is the route being registered
+	Pattern string
+	// Existing is the registered route it conflicts with, if any
+	Existing string
+	Err      error
+}
+
+func (e *RouteError) Error() string {
+	s := e.Pattern + ": " + e.Err.Error()
+	if e.Method != "" {
+		s = e.Method + " " + s
+	}
+	if e.Existing != "" {
+		s += " with " + e.Existing
+	}
+	return s
+}
+
+func (e *RouteError) Unwrap() error {
+	return e.Err
+}
+
+// ParamError is returned by the typed param getters, such as GetInt.
+// Err is ErrMissingParam or ErrInvalidParam, possibly wrapped with details
+type ParamError struct {
+	Name  string
+	Value string
+	Err   error
+}
+
+func (e *ParamError) Error() string {
+	return "param " + e.Name + ": " + e.Err.Error()
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// invalidParam returns a *ParamError for a value which is not of the type
+func invalidParam(name, value, typ string) *ParamError {
+	return &ParamError{Name: name, Value: value, Err: invalidValue(value, typ)}
+}
+
+// invalidValue returns ErrInvalidParam wrapped with the value and type
+func invalidValue(value, typ string) error {
+	return fmt.Errorf("%w: %q is not a valid %s", ErrInvalidParam, value, typ)
+}
+
+// FieldError is a struct field which could not be bound, see Bind.
+// Err is ErrMissingParam or ErrInvalidParam, possibly wrapped with details
+type FieldError struct {
+	// Field is the struct field name, empty for the body
+	Field string
+	// Source is path, query, header or body
+	Source string
+	// Name is the param, query or header name from the tag
+	Name  string
+	Value string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	if e.Name == "" {
+		return e.Source + ": " + e.Err.Error()
+	}
+	return e.Source + " " + e.Name + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// BindError is returned by Bind and lists every
+// field which could not be bound
+type BindError []*FieldError
+
+func (e BindError) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// Unwrap returns the field errors for errors.Is and errors.As
+func (e BindError) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// HandleParamError writes a 400 Bad Request response with the error,
+// such as a *ParamError or a BindError, as text and returns true
+// if err is not nil, so a handler can stop
+//
+//	id, err := r2router.GetInt(p, "id")
+//	if r2router.HandleParamError(w, err) {
+//		return
+//	}
+func HandleParamError(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		return false
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+	return true
+}
@@ -0,0 +1,86 @@
+package r2router
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestRouteErrorDuplicate(t *testing.T) {
+	r := newRouteTree()
+	assert.Nil(t, r.tryAddRoute("/users/:user/events", &httpTestHandler{}))
+	err := r.tryAddRoute("/users/:user/events/", &httpTestHandler{})
+	assert.True(t, errors.Is(err, ErrDuplicateRoute))
+	assert.Equal(t, err.Error(), "/users/:user/events/: duplicate route with /users/:user/events")
+
+	assert.Nil(t, r.tryAddRoute("/", &httpTestHandler{}))
+	err = r.tryAddRoute("/", &httpTestHandler{})
+	assert.True(t, errors.Is(err, ErrDuplicateRoute))
+}
+
+func TestRouteErrorParamConflict(t *testing.T) {
+	r := newRouteTree()
+	assert.Nil(t, r.tryAddRoute("/users/:username/following/:target_user", &httpTestHandler{}))
+	err := r.tryAddRoute("/users/:user/events", &httpTestHandler{})
+	assert.True(t, errors.Is(err, ErrParamConflict))
+	rerr, ok := err.(*RouteError)
+	assert.True(t, ok)
+	assert.Equal(t, rerr.Pattern, "/users/:user/events")
+	assert.Equal(t, rerr.Existing, "/users/:username/following/:target_user")
+	assert.Equal(t, err.Error(), "/users/:user/events: param conflict: param name must be same, :user and :username with /users/:username/following/:target_user")
+
+	assert.Nil(t, r.tryAddRoute("/docs/:lang?", &httpTestHandler{}))
+	err = r.tryAddRoute("/docs/:lang?=en/index", &httpTestHandler{})
+	assert.True(t, errors.Is(err, ErrParamConflict))
+
+	assert.Nil(t, r.tryAddRoute("/static/*filepath", &httpTestHandler{}))
+	err = r.tryAddRoute("/static/*rest", &httpTestHandler{})
+	assert.True(t, errors.Is(err, ErrParamConflict))
+	assert.Equal(t, err.(*RouteError).Existing, "/static/*filepath")
+}
+
+func TestRouteErrorInvalid(t *testing.T) {
+	for _, path := range []string{
+		"/users/:/following",
+		"/users//following",
+		"/static/*",
+		"/static/*filepath/more",
+		"/v:major:minor",
+		"/users/:id(\\d+",
+		"/users/:id<unknown>",
+		"/users/:id([)",
+		"/files/:name?.json",
+	} {
+		err := newRouteTree().tryAddRoute(path, &httpTestHandler{})
+		assert.True(t, errors.Is(err, ErrInvalidRoute), path)
+		assert.Equal(t, err.(*RouteError).Pattern, path)
+	}
+}
+
+func TestRouteErrorMethod(t *testing.T) {
+	err := &RouteError{Method: "GET", Pattern: "/a", Existing: "/a/", Err: ErrDuplicateRoute}
+	assert.Equal(t, err.Error(), "GET /a: duplicate route with /a/")
+	assert.True(t, errors.Is(err, ErrDuplicateRoute))
+	err = &RouteError{Pattern: "/a", Err: ErrInvalidRoute}
+	assert.Equal(t, err.Error(), "/a: invalid route")
+}
+
+func TestHandleParamError(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		id, err := GetInt(p, "id")
+		if HandleParamError(w, err) {
+			return
+		}
+		w.Write([]byte(strconv.Itoa(id)))
+	})
+
+	res := serveHost(router, "GET", "", "/users/12")
+	assert.Equal(t, res.Code, http.StatusOK)
+	assert.Equal(t, res.Body.String(), "12")
+	res = serveHost(router, "GET", "", "/users/abc")
+	assert.Equal(t, res.Code, http.StatusBadRequest)
+	assert.Equal(t, res.Body.String(), "param id: invalid param: \"abc\" is not a valid int\n")
+}
@@ -0,0 +1,160 @@
+package r2router
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TreeNode is a node of an exported route tree, see Router.Tree
+type TreeNode struct {
+	// Kind is static, pattern, param or wildcard
+	Kind string `json:"kind"`
+	// Path is the static segments from the parent, the param
+	// with constraint and optional marker, :id(\d+), the catch-all,
+	// *filepath, or static text and params, :name.:ext
+	Path string `json:"path"`
+	// Pattern is the registered route, only set with a handler
+	Pattern string `json:"pattern,omitempty"`
+	// Handler is the function name of the handler
+	Handler  string      `json:"handler,omitempty"`
+	Children []*TreeNode `json:"children,omitempty"`
+}
+
+// Tree returns the route tree of each method. Static children are
+// sorted by path, pattern and param children are in the order they
+// are tried, which depends on the order the routes were added
+func (r *Router) Tree() map[string]*TreeNode {
+	trees := make(map[string]*TreeNode)
+	for method, root := range r.trees() {
+		trees[method] = root.export()
+	}
+	return trees
+}
+
+// ExportJSON returns the route trees as indented JSON,
+// an object with a tree per method
+func (r *Router) ExportJSON() ([]byte, error) {
+	return json.MarshalIndent(r.Tree(), "", "  ")
+}
+
+// ExportDOT returns the route trees as a Graphviz DOT graph
+// with a cluster per method. Static nodes are boxes, params
+// ellipses, catch-all diamonds and routes have a double border
+func (r *Router) ExportDOT() string {
+	roots := r.trees()
+	s := "digraph routes {\n"
+	for _, method := range sortedMethods(roots) {
+		s += fmt.Sprintf("  subgraph %s {\n    label=%s;\n", dotQuote("cluster_"+method), dotQuote(method))
+		id := 0
+		var dotNode func(node *TreeNode) string
+		dotNode = func(node *TreeNode) string {
+			name := dotQuote(fmt.Sprintf("%s_%d", method, id))
+			id++
+			label := node.Path
+			attrs := ""
+			switch node.Kind {
+			case "param":
+				attrs = "shape=ellipse"
+			case "wildcard":
+				attrs = "shape=diamond"
+			default:
+				attrs = "shape=box"
+			}
+			if node.Handler != "" {
+				label += "\n" + node.Handler
+				attrs += " peripheries=2"
+			}
+			s += fmt.Sprintf("    %s [label=%s %s];\n", name, dotQuote(label), attrs)
+			for _, c := range node.Children {
+				s += fmt.Sprintf("    %s -> %s;\n", name, dotNode(c))
+			}
+			return name
+		}
+		dotNode(roots[method].export())
+		s += "  }\n"
+	}
+	return s + "}\n"
+}
+
+// export converts the tree, the root is the node for /
+func (n *rootNode) export() *TreeNode {
+	var exportNode func(node *routeNode) *TreeNode
+	exportNode = func(node *routeNode) *TreeNode {
+		tn := &TreeNode{Kind: "static", Path: node.path}
+		switch {
+		case node.paramNode:
+			tn.Kind = "param"
+			tn.Path = ":" + node.paramName + node.constraint
+			if node.optional {
+				tn.Path += "?"
+				if node.defaultValue != "" {
+					tn.Path += "=" + node.defaultValue
+				}
+			}
+		case node.wildcardNode:
+			tn.Kind = "wildcard"
+			tn.Path = "*" + node.paramName
+		case node.patternNode:
+			tn.Kind = "pattern"
+		}
+		route, slash := node.leaves[0], node.leaves[slashLeaf]
+		if route == nil {
+			route, slash = slash, nil
+		}
+		if route != nil {
+			tn.Pattern = route.pattern
+			tn.Handler = handlerName(route.handler)
+		}
+		if slash != nil {
+			// the route with a trailing slash if both are registered
+			tn.Children = append(tn.Children, &TreeNode{Kind: "static", Path: "/",
+				Pattern: slash.pattern, Handler: handlerName(slash.handler)})
+		}
+		static := make([]*TreeNode, 0, len(node.children))
+		for _, c := range node.children {
+			static = append(static, exportNode(c))
+		}
+		sort.Slice(static, func(i, j int) bool {
+			return static[i].Path < static[j].Path
+		})
+		tn.Children = append(tn.Children, static...)
+		for _, c := range node.patternChildren {
+			tn.Children = append(tn.Children, exportNode(c))
+		}
+		for _, c := range node.paramChildren {
+			tn.Children = append(tn.Children, exportNode(c))
+		}
+		if node.wildcardChild != nil {
+			tn.Children = append(tn.Children, exportNode(node.wildcardChild))
+		}
+		return tn
+	}
+
+	tn := exportNode(n.root)
+	tn.Path = "/"
+	if n.index != nil {
+		tn.Pattern = n.index.pattern
+		tn.Handler = handlerName(n.index.handler)
+	}
+	return tn
+}
+
+// sortedMethods returns the methods of the trees in order
+func sortedMethods(roots map[string]*rootNode) []string {
+	methods := make([]string, 0, len(roots))
+	for method := range roots {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// dotQuote quotes s as a DOT string
+func dotQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return `"` + s + `"`
+}
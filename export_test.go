@@ -0,0 +1,128 @@
+package r2router
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func exportTestHandler(w http.ResponseWriter, req *http.Request, p Params) {}
+
+func TestRouterTree(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id", exportTestHandler)
+	router.Get("/", exportTestHandler)
+	router.Get("/static/*filepath", exportTestHandler)
+	router.Get(`/files/:id(\d+)?`, exportTestHandler)
+	router.Get("/files/:name.:ext", exportTestHandler)
+	router.Post("/users", exportTestHandler)
+
+	trees := router.Tree()
+	assert.Equal(t, len(trees), 2)
+	get := trees["GET"]
+	assert.Equal(t, get.Path, "/")
+	assert.Equal(t, get.Pattern, "/")
+	assert.Equal(t, get.Handler, "github.com/vanng822/r2router.exportTestHandler")
+	assert.Equal(t, len(get.Children), 3)
+	// sorted, not in the order they were added
+	assert.Equal(t, get.Children[0].Path, "files")
+	assert.Equal(t, get.Children[0].Handler, "")
+	assert.Equal(t, get.Children[1].Path, "static")
+	assert.Equal(t, get.Children[2].Path, "users")
+
+	files := get.Children[0].Children
+	assert.Equal(t, files[0].Kind, "pattern")
+	assert.Equal(t, files[0].Path, ":name.:ext")
+	assert.Equal(t, files[1].Kind, "param")
+	assert.Equal(t, files[1].Path, `:id(\d+)?`)
+	assert.Equal(t, files[1].Pattern, `/files/:id(\d+)?`)
+
+	wildcard := get.Children[1].Children[0]
+	assert.Equal(t, wildcard.Kind, "wildcard")
+	assert.Equal(t, wildcard.Path, "*filepath")
+	assert.Equal(t, wildcard.Pattern, "/static/*filepath")
+}
+
+func TestRouterExportJSON(t *testing.T) {
+	router := NewRouter()
+	router.Post("/users", exportTestHandler)
+	router.Get("/users/:id", exportTestHandler)
+
+	data, err := router.ExportJSON()
+	assert.Nil(t, err)
+	assert.Equal(t, string(data), `{
+  "GET": {
+    "kind": "static",
+    "path": "/",
+    "children": [
+      {
+        "kind": "static",
+        "path": "users",
+        "children": [
+          {
+            "kind": "param",
+            "path": ":id",
+            "pattern": "/users/:id",
+            "handler": "github.com/vanng822/r2router.exportTestHandler"
+          }
+        ]
+      }
+    ]
+  },
+  "POST": {
+    "kind": "static",
+    "path": "/",
+    "children": [
+      {
+        "kind": "static",
+        "path": "users",
+        "pattern": "/users",
+        "handler": "github.com/vanng822/r2router.exportTestHandler"
+      }
+    ]
+  }
+}`)
+}
+
+func TestRouterExportDeterministic(t *testing.T) {
+	paths := []string{"/b/:id", "/a", "/c/d", "/c/e", "/a/b"}
+	first := NewRouter()
+	second := NewRouter()
+	for i := range paths {
+		first.Get(paths[i], exportTestHandler)
+		first.Put(paths[i], exportTestHandler)
+		second.Put(paths[len(paths)-1-i], exportTestHandler)
+		second.Get(paths[len(paths)-1-i], exportTestHandler)
+	}
+	firstJSON, _ := first.ExportJSON()
+	secondJSON, _ := second.ExportJSON()
+	assert.Equal(t, string(firstJSON), string(secondJSON))
+	assert.Equal(t, first.ExportDOT(), second.ExportDOT())
+	assert.Equal(t, first.Dump(), second.Dump())
+}
+
+func TestRouterExportDOT(t *testing.T) {
+	router := NewRouter()
+	router.Get(`/users/:id(\d+)`, exportTestHandler)
+	router.Delete("/static/*filepath", exportTestHandler)
+
+	assert.Equal(t, router.ExportDOT(), `digraph routes {
+  subgraph "cluster_DELETE" {
+    label="DELETE";
+    "DELETE_0" [label="/" shape=box];
+    "DELETE_1" [label="static" shape=box];
+    "DELETE_2" [label="*filepath\ngithub.com/vanng822/r2router.exportTestHandler" shape=diamond peripheries=2];
+    "DELETE_1" -> "DELETE_2";
+    "DELETE_0" -> "DELETE_1";
+  }
+  subgraph "cluster_GET" {
+    label="GET";
+    "GET_0" [label="/" shape=box];
+    "GET_1" [label="users" shape=box];
+    "GET_2" [label=":id(\\d+)\ngithub.com/vanng822/r2router.exportTestHandler" shape=ellipse peripheries=2];
+    "GET_1" -> "GET_2";
+    "GET_0" -> "GET_1";
+  }
+}
+`)
+}
@@ -11,6 +11,11 @@ import (
 type GroupRouter struct {
 	router *Router
 	path   string
+	// afters are the middlewares of the group,
+	// they run before those of each route
+	afters     []After
+	namePrefix string
+	meta       map[string]interface{}
 }
 
 // NewGroupRouter return GroupRouter which is a helper
@@ -24,30 +29,101 @@ func NewGroupRouter(r *Router, path string) *GroupRouter {
 	return gr
 }
 
+// buildPath joins the path to the group path,
+// an empty path is the group path itself
 func (gr *GroupRouter) buildPath(path string) string {
+	if path == "" {
+		if gr.path == "" {
+			return "/"
+		}
+		return gr.path
+	}
 	return gr.path + "/" + strings.TrimLeft(path, "/")
 }
 
-func (gr *GroupRouter) Get(path string, handler HandlerFunc) {
-	gr.router.Get(gr.buildPath(path), handler)
+// Group creates a group below this group, the path is joined
+// to the group path. The nested group inherits the middlewares,
+// name prefix and metadata of this group. The middlewares given
+// run after those of this group
+func (gr *GroupRouter) Group(path string, fn func(r *GroupRouter), middlewares ...After) {
+	sub := NewGroupRouter(gr.router, gr.buildPath(path))
+	sub.afters = joinAfters(gr.afters, middlewares)
+	sub.namePrefix = gr.namePrefix
+	sub.meta = gr.meta
+	fn(sub)
 }
 
-func (gr *GroupRouter) Head(path string, handler HandlerFunc) {
-	gr.router.Head(gr.buildPath(path), handler)
+// Name adds a prefix to the names of routes added to the group
+// and its nested groups afterwards, such as
+// r.Name("admin.").Get("/users", users).Name("users") is named "admin.users"
+func (gr *GroupRouter) Name(prefix string) *GroupRouter {
+	gr.namePrefix += prefix
+	return gr
 }
 
-func (gr *GroupRouter) Post(path string, handler HandlerFunc) {
-	gr.router.Post(gr.buildPath(path), handler)
+// Meta sets metadata for routes added to the group
+// and its nested groups afterwards
+func (gr *GroupRouter) Meta(key string, value interface{}) *GroupRouter {
+	meta := make(map[string]interface{}, len(gr.meta)+1)
+	for k, v := range gr.meta {
+		meta[k] = v
+	}
+	meta[key] = value
+	// shared with routes and nested groups, so it is never changed
+	gr.meta = meta
+	return gr
 }
 
-func (gr *GroupRouter) Put(path string, handler HandlerFunc) {
-	gr.router.Put(gr.buildPath(path), handler)
+// add registers the handler wrapped in the middlewares
+// of the group and those given for the route
+func (gr *GroupRouter) add(methods []string, path string, handler Handler, middlewares []After) []*Route {
+	routes := gr.router.mustAdd(methods, gr.buildPath(path), chain(handler, joinAfters(gr.afters, middlewares)))
+	if gr.namePrefix != "" || gr.meta != nil {
+		gr.router.mu.Lock()
+		defer gr.router.mu.Unlock()
+		for _, route := range routes {
+			route.namePrefix = gr.namePrefix
+			route.meta = gr.meta
+		}
+	}
+	return routes
 }
 
-func (gr *GroupRouter) Delete(path string, handler HandlerFunc) {
-	gr.router.Delete(gr.buildPath(path), handler)
+// joinAfters returns a new slice with the middlewares of a then b
+func joinAfters(a, b []After) []After {
+	afters := make([]After, 0, len(a)+len(b))
+	afters = append(afters, a...)
+	return append(afters, b...)
 }
 
-func (gr *GroupRouter) Patch(path string, handler HandlerFunc) {
-	gr.router.Patch(gr.buildPath(path), handler)
-}
\ No newline at end of file
+func (gr *GroupRouter) Get(path string, handler HandlerFunc, middlewares ...After) *Route {
+	return gr.add([]string{HTTP_METHOD_GET}, path, handler, middlewares)[0]
+}
+
+func (gr *GroupRouter) Head(path string, handler HandlerFunc, middlewares ...After) *Route {
+	return gr.add([]string{HTTP_METHOD_HEAD}, path, handler, middlewares)[0]
+}
+
+func (gr *GroupRouter) Post(path string, handler HandlerFunc, middlewares ...After) *Route {
+	return gr.add([]string{HTTP_METHOD_POST}, path, handler, middlewares)[0]
+}
+
+func (gr *GroupRouter) Put(path string, handler HandlerFunc, middlewares ...After) *Route {
+	return gr.add([]string{HTTP_METHOD_PUT}, path, handler, middlewares)[0]
+}
+
+func (gr *GroupRouter) Delete(path string, handler HandlerFunc, middlewares ...After) *Route {
+	return gr.add([]string{HTTP_METHOD_DELETE}, path, handler, middlewares)[0]
+}
+
+func (gr *GroupRouter) Patch(path string, handler HandlerFunc, middlewares ...After) *Route {
+	return gr.add([]string{HTTP_METHOD_PATCH}, path, handler, middlewares)[0]
+}
+
+func (gr *GroupRouter) Handle(methods []string, path string, handler HandlerFunc, middlewares ...After) []*Route {
+	return gr.add(methods, path, handler, middlewares)
+}
+
+func (gr *GroupRouter) Any(path string, handler HandlerFunc, middlewares ...After) *Route {
+	return gr.add([]string{HTTP_METHOD_ANY}, path, handler, middlewares)[0]
+}
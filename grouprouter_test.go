@@ -93,3 +93,102 @@ func TestRouterGroup(t *testing.T) {
 	// http seems not sending content
 	assert.Equal(t, int(res.ContentLength), len([]byte("PATCH:/user/keys/:id,testing")))
 }
+
+func TestRouterGroupWildcard(t *testing.T) {
+	router := NewRouter()
+	router.Group("/files", func(r *GroupRouter) {
+		r.Get("/*filepath", func(w http.ResponseWriter, r *http.Request, p Params) {
+			w.Write([]byte("GET:/files/*filepath," + p.Get("filepath")))
+		})
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/files/docs/2015/report.pdf")
+	assert.Nil(t, err)
+	content, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, string(content), "GET:/files/*filepath,docs/2015/report.pdf")
+}
+
+func TestRouterGroupHandle(t *testing.T) {
+	router := NewRouter()
+	router.Group("/dav", func(r *GroupRouter) {
+		r.Handle([]string{"PROPFIND", "MKCOL"}, "/:name", func(w http.ResponseWriter, r *http.Request, p Params) {
+			w.Write([]byte(r.Method + ":" + p.Get("name")))
+		})
+		r.Any("/*rest", func(w http.ResponseWriter, r *http.Request, p Params) {
+			w.Write([]byte("any:" + p.Get("rest")))
+		})
+	})
+	w := serveHost(router, "MKCOL", "example.com", "/dav/docs")
+	assert.Equal(t, w.Body.String(), "MKCOL:docs")
+	w = serveHost(router, "GET", "example.com", "/dav/docs")
+	assert.Equal(t, w.Body.String(), "any:docs")
+}
+
+func TestRouterGroupNested(t *testing.T) {
+	router := NewRouter()
+	router.Group("/api", func(r *GroupRouter) {
+		r.Get("", func(w http.ResponseWriter, r *http.Request, p Params) {
+			w.Write([]byte("api"))
+		})
+		r.Group("/v1/", func(r *GroupRouter) {
+			r.Group("users/:id", func(r *GroupRouter) {
+				r.Get("", func(w http.ResponseWriter, r *http.Request, p Params) {
+					w.Write([]byte("user:" + p.Get("id")))
+				})
+				r.Get("/keys", func(w http.ResponseWriter, r *http.Request, p Params) {
+					w.Write([]byte("keys:" + p.Get("id")))
+				})
+			})
+		})
+	})
+	router.Group("/", func(r *GroupRouter) {
+		r.Get("", func(w http.ResponseWriter, r *http.Request, p Params) {
+			w.Write([]byte("index"))
+		})
+	})
+
+	patterns := []string{}
+	for _, route := range router.Routes() {
+		patterns = append(patterns, route.Pattern)
+	}
+	assert.Equal(t, patterns, []string{"/api", "/api/v1/users/:id", "/api/v1/users/:id/keys", "/"})
+
+	assert.Equal(t, serveHost(router, "GET", "", "/api").Body.String(), "api")
+	assert.Equal(t, serveHost(router, "GET", "", "/api/v1/users/1").Body.String(), "user:1")
+	assert.Equal(t, serveHost(router, "GET", "", "/api/v1/users/1/keys").Body.String(), "keys:1")
+	assert.Equal(t, serveHost(router, "GET", "", "/").Body.String(), "index")
+}
+
+func TestRouterGroupNestedSettings(t *testing.T) {
+	router := NewSeeforRouter()
+	handler := func(w http.ResponseWriter, r *http.Request, p Params) {
+		trace, _ := p.AppGet("trace").(string)
+		w.Write([]byte(trace + "handler"))
+	}
+	router.Group("/admin", func(r *GroupRouter) {
+		r.Name("admin.").Meta("auth", true)
+		r.Get("/", handler).Name("index")
+		r.Group("/users", func(r *GroupRouter) {
+			r.Name("users.").Meta("role", "admin")
+			r.Get("/:id", handler, traceAfter("route")).Name("show")
+		}, traceAfter("users"))
+		r.Get("/settings", handler)
+	}, traceAfter("admin"))
+
+	routes := router.Routes()
+	assert.Equal(t, len(routes), 3)
+	assert.Equal(t, routes[0].Name, "admin.index")
+	assert.Equal(t, routes[0].Meta, map[string]interface{}{"auth": true})
+	assert.Equal(t, routes[1].Name, "admin.users.show")
+	assert.Equal(t, routes[1].Meta, map[string]interface{}{"auth": true, "role": "admin"})
+	assert.Equal(t, routes[2].Name, "")
+	assert.Equal(t, routes[2].Meta, map[string]interface{}{"auth": true})
+
+	assert.Equal(t, serveHost(router, "GET", "", "/admin").Body.String(), "admin,handler")
+	assert.Equal(t, serveHost(router, "GET", "", "/admin/users/1").Body.String(), "admin,users,route,handler")
+	assert.Equal(t, serveHost(router, "GET", "", "/admin/settings").Body.String(), "admin,handler")
+}
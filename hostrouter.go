@@ -0,0 +1,131 @@
+package r2router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// hostParamsKey is the request context key for
+// params matched from the host by HostRouter
+type hostParamsKey struct{}
+
+type hostRoute struct {
+	pattern string
+	labels  []string
+	handler http.Handler
+}
+
+// match matches the host labels against the pattern
+// and returns the params from it
+func (h *hostRoute) match(labels []string) ([]param, bool) {
+	if len(labels) != len(h.labels) {
+		return nil, false
+	}
+	params := make([]param, 0, 1)
+	for i, label := range h.labels {
+		if label[0] == ':' {
+			if labels[i] == "" {
+				return nil, false
+			}
+			params = append(params, param{label[1:], labels[i]})
+			continue
+		}
+		if label != labels[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// HostRouter dispatches requests to a handler registered for
+// the host of the request, typically a Router or Seefor per host.
+// Each host has its own routes so OPTIONS and 405 are per host too
+type HostRouter struct {
+	hosts    map[string]http.Handler
+	patterns []*hostRoute
+	// Fallback handles requests for hosts without a handler.
+	// If it is nil the response is 404
+	Fallback http.Handler
+}
+
+// NewHostRouter return a new HostRouter
+func NewHostRouter() *HostRouter {
+	hr := &HostRouter{}
+	hr.hosts = make(map[string]http.Handler)
+	hr.patterns = make([]*hostRoute, 0)
+	return hr
+}
+
+// Host registers the handler for requests to the host pattern.
+// A label starting with colon is a param, :tenant.api.example.com,
+// which is available through Params.Get in Router and Seefor.
+// Matching is case-insensitive and the port is ignored.
+// Hosts without params are tried first and then patterns
+// in the order they were registered
+func (hr *HostRouter) Host(pattern string, handler http.Handler) {
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+	if pattern == "" || handler == nil {
+		panic("Host pattern and handler can not be empty")
+	}
+	if strings.IndexByte(pattern, ':') == -1 {
+		if _, exists := hr.hosts[pattern]; exists {
+			panic(fmt.Sprintf("'%s' has already a handler", pattern))
+		}
+		hr.hosts[pattern] = handler
+		return
+	}
+	for _, h := range hr.patterns {
+		if h.pattern == pattern {
+			panic(fmt.Sprintf("'%s' has already a handler", pattern))
+		}
+	}
+	labels := strings.Split(pattern, ".")
+	for _, label := range labels {
+		if label == "" || label == ":" {
+			panic(fmt.Sprintf("'%s' has an empty label", pattern))
+		}
+		if strings.IndexByte(label[1:], ':') != -1 {
+			panic(fmt.Sprintf("'%s' has a port or a param inside a label", pattern))
+		}
+	}
+	hr.patterns = append(hr.patterns, &hostRoute{pattern, labels, handler})
+}
+
+// http Handler Interface
+func (hr *HostRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	host := hostname(req.Host)
+	if handler, exists := hr.hosts[host]; exists {
+		handler.ServeHTTP(w, req)
+		return
+	}
+	if len(hr.patterns) > 0 {
+		labels := strings.Split(host, ".")
+		for _, h := range hr.patterns {
+			if params, ok := h.match(labels); ok {
+				ctx := context.WithValue(req.Context(), hostParamsKey{}, params)
+				h.handler.ServeHTTP(w, req.WithContext(ctx))
+				return
+			}
+		}
+	}
+	if hr.Fallback != nil {
+		hr.Fallback.ServeHTTP(w, req)
+		return
+	}
+	http.NotFound(w, req)
+}
+
+// hostname returns the host in lower case without port
+func hostname(host string) string {
+	if strings.HasPrefix(host, "[") {
+		// IPv6 literal
+		if i := strings.IndexByte(host, ']'); i != -1 {
+			host = host[:i+1]
+		}
+	} else if i := strings.IndexByte(host, ':'); i != -1 {
+		host = host[:i]
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
@@ -0,0 +1,133 @@
+package r2router
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveHost(h http.Handler, method, host, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, "http://"+host+path, nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHostRouter(t *testing.T) {
+	api := NewRouter()
+	api.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("api:" + p.Get("id")))
+	})
+	tenant := NewRouter()
+	tenant.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(p.Get("tenant") + ":" + p.Get("id")))
+	})
+	tenant.Post("/orders", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(p.Get("tenant") + ":order"))
+	})
+
+	hr := NewHostRouter()
+	hr.Host("api.example.com", api)
+	hr.Host(":tenant.api.example.com", tenant)
+
+	w := serveHost(hr, "GET", "api.example.com", "/users/1")
+	assert.Equal(t, w.Body.String(), "api:1")
+
+	w = serveHost(hr, "GET", "API.Example.com:8080", "/users/1")
+	assert.Equal(t, w.Body.String(), "api:1")
+
+	w = serveHost(hr, "GET", "acme.api.example.com", "/users/2")
+	assert.Equal(t, w.Body.String(), "acme:2")
+
+	w = serveHost(hr, "POST", "acme.api.example.com:443", "/orders")
+	assert.Equal(t, w.Body.String(), "acme:order")
+
+	w = serveHost(hr, "GET", "a.b.api.example.com", "/users/2")
+	assert.Equal(t, w.Code, http.StatusNotFound)
+
+	w = serveHost(hr, "GET", "example.com", "/users/2")
+	assert.Equal(t, w.Code, http.StatusNotFound)
+}
+
+func TestHostRouterPerHostMissing(t *testing.T) {
+	api := NewRouter()
+	api.Get("/users", func(w http.ResponseWriter, r *http.Request, p Params) {})
+	admin := NewRouter()
+	admin.Post("/users", func(w http.ResponseWriter, r *http.Request, p Params) {})
+
+	hr := NewHostRouter()
+	hr.Host("api.example.com", api)
+	hr.Host("admin.example.com", admin)
+
+	w := serveHost(hr, "OPTIONS", "api.example.com", "/users")
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Header().Get("Allow"), "GET")
+
+	w = serveHost(hr, "OPTIONS", "admin.example.com", "/users")
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Header().Get("Allow"), "POST")
+
+	w = serveHost(hr, "POST", "api.example.com", "/users")
+	assert.Equal(t, w.Code, http.StatusMethodNotAllowed)
+
+	w = serveHost(hr, "GET", "admin.example.com", "/users")
+	assert.Equal(t, w.Code, http.StatusMethodNotAllowed)
+}
+
+func TestHostRouterFallback(t *testing.T) {
+	fallback := NewRouter()
+	fallback.Get("/", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("fallback:" + p.Get("tenant")))
+	})
+	hr := NewHostRouter()
+	hr.Host(":tenant.example.com", NewRouter())
+	hr.Fallback = fallback
+
+	w := serveHost(hr, "GET", "localhost:8080", "/")
+	assert.Equal(t, w.Body.String(), "fallback:")
+
+	w = serveHost(hr, "GET", "[::1]:8080", "/")
+	assert.Equal(t, w.Body.String(), "fallback:")
+}
+
+func TestHostRouterSeefor(t *testing.T) {
+	seefor := NewSeeforRouter()
+	seefor.Get("/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(p.Get("tenant") + ":" + p.Get("id")))
+	})
+	hr := NewHostRouter()
+	hr.Host(":tenant.example.com", seefor)
+
+	w := serveHost(hr, "GET", "acme.example.com", "/3")
+	assert.Equal(t, w.Body.String(), "acme:3")
+}
+
+func TestHostRouterPanic(t *testing.T) {
+	hr := NewHostRouter()
+	hr.Host("example.com", NewRouter())
+	hr.Host(":tenant.example.com", NewRouter())
+	assert.Panics(t, func() {
+		hr.Host("Example.com", NewRouter())
+	})
+	assert.Panics(t, func() {
+		hr.Host(":tenant.example.com", NewRouter())
+	})
+	assert.Panics(t, func() {
+		hr.Host(":.example.com", NewRouter())
+	})
+	assert.Panics(t, func() {
+		hr.Host("example.com:8080", NewRouter())
+	})
+	assert.Panics(t, func() {
+		hr.Host("", NewRouter())
+	})
+}
+
+func TestHostname(t *testing.T) {
+	assert.Equal(t, hostname("Example.COM:8080"), "example.com")
+	assert.Equal(t, hostname("example.com."), "example.com")
+	assert.Equal(t, hostname("[::1]:80"), "[::1]")
+	assert.Equal(t, hostname("[::1]"), "[::1]")
+	assert.Equal(t, hostname("localhost"), "localhost")
+}
@@ -0,0 +1,86 @@
+package r2router
+
+import (
+	"context"
+	"net/http"
+)
+
+// httpHandler serves an http.Handler route,
+// the params are in the request context
+type httpHandler struct {
+	handler http.Handler
+}
+
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, params Params) {
+	h.handler.ServeHTTP(w, withParams(req, params))
+}
+
+// withParams returns the request with params in its context,
+// the request itself if they are there already
+func withParams(req *http.Request, params Params) *http.Request {
+	if p, ok := req.Context().Value(paramsKey{}).(Params); ok && p == params {
+		return req
+	}
+	return req.WithContext(context.WithValue(req.Context(), paramsKey{}, params))
+}
+
+func (r *Router) GetHandler(path string, handler http.Handler) *Route {
+	return r.AddHTTPHandler(HTTP_METHOD_GET, path, handler)
+}
+
+func (r *Router) HeadHandler(path string, handler http.Handler) *Route {
+	return r.AddHTTPHandler(HTTP_METHOD_HEAD, path, handler)
+}
+
+func (r *Router) PostHandler(path string, handler http.Handler) *Route {
+	return r.AddHTTPHandler(HTTP_METHOD_POST, path, handler)
+}
+
+func (r *Router) PutHandler(path string, handler http.Handler) *Route {
+	return r.AddHTTPHandler(HTTP_METHOD_PUT, path, handler)
+}
+
+func (r *Router) DeleteHandler(path string, handler http.Handler) *Route {
+	return r.AddHTTPHandler(HTTP_METHOD_DELETE, path, handler)
+}
+
+func (r *Router) PatchHandler(path string, handler http.Handler) *Route {
+	return r.AddHTTPHandler(HTTP_METHOD_PATCH, path, handler)
+}
+
+// AddHTTPHandler registers an http.Handler for method and path.
+// The handler gets the params using ParamsFromContext,
+// they are always in the context for such routes.
+// It panics as AddHandler
+func (r *Router) AddHTTPHandler(method, path string, handler http.Handler) *Route {
+	return r.mustAdd([]string{method}, path, &httpHandler{handler})[0]
+}
+
+func (gr *GroupRouter) GetHandler(path string, handler http.Handler, middlewares ...After) *Route {
+	return gr.AddHTTPHandler(HTTP_METHOD_GET, path, handler, middlewares...)
+}
+
+func (gr *GroupRouter) HeadHandler(path string, handler http.Handler, middlewares ...After) *Route {
+	return gr.AddHTTPHandler(HTTP_METHOD_HEAD, path, handler, middlewares...)
+}
+
+func (gr *GroupRouter) PostHandler(path string, handler http.Handler, middlewares ...After) *Route {
+	return gr.AddHTTPHandler(HTTP_METHOD_POST, path, handler, middlewares...)
+}
+
+func (gr *GroupRouter) PutHandler(path string, handler http.Handler, middlewares ...After) *Route {
+	return gr.AddHTTPHandler(HTTP_METHOD_PUT, path, handler, middlewares...)
+}
+
+func (gr *GroupRouter) DeleteHandler(path string, handler http.Handler, middlewares ...After) *Route {
+	return gr.AddHTTPHandler(HTTP_METHOD_DELETE, path, handler, middlewares...)
+}
+
+func (gr *GroupRouter) PatchHandler(path string, handler http.Handler, middlewares ...After) *Route {
+	return gr.AddHTTPHandler(HTTP_METHOD_PATCH, path, handler, middlewares...)
+}
+
+// AddHTTPHandler is as Router.AddHTTPHandler for the group
+func (gr *GroupRouter) AddHTTPHandler(method, path string, handler http.Handler, middlewares ...After) *Route {
+	return gr.add([]string{method}, path, &httpHandler{handler}, middlewares)[0]
+}
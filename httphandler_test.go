@@ -0,0 +1,73 @@
+package r2router
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+// paramsWriter writes the id param read from the request context
+func paramsWriter(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(r.Method + ":" + ParamsFromContext(r.Context()).Get("id")))
+}
+
+func TestRouterHTTPHandler(t *testing.T) {
+	router := NewRouter()
+	router.GetHandler("/users/:id", http.HandlerFunc(paramsWriter))
+	router.HeadHandler("/users/:id", http.HandlerFunc(paramsWriter))
+	router.PostHandler("/users/:id", http.HandlerFunc(paramsWriter))
+	router.PutHandler("/users/:id", http.HandlerFunc(paramsWriter))
+	router.DeleteHandler("/users/:id", http.HandlerFunc(paramsWriter))
+	router.PatchHandler("/users/:id", http.HandlerFunc(paramsWriter))
+	router.AddHTTPHandler("PROPFIND", "/users/:id", http.HandlerFunc(paramsWriter))
+	router.Get("/plain/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(p.Get("id") + ":" + ParamsFromContext(r.Context()).Get("id")))
+	})
+
+	for _, method := range []string{"GET", "HEAD", "POST", "PUT", "DELETE", "PATCH", "PROPFIND"} {
+		assert.Equal(t, serveHost(router, method, "", "/users/1").Body.String(), method+":1")
+	}
+	// not in the context by default
+	assert.Equal(t, serveHost(router, "GET", "", "/plain/1").Body.String(), "1:")
+	router.ParamsInContext = true
+	assert.Equal(t, serveHost(router, "GET", "", "/plain/1").Body.String(), "1:1")
+	assert.Equal(t, serveHost(router, "GET", "", "/users/1").Body.String(), "GET:1")
+
+	assert.Equal(t, router.Routes()[0].Handler, "github.com/vanng822/r2router.paramsWriter")
+}
+
+func TestSeeforHTTPHandler(t *testing.T) {
+	router := NewSeeforRouter()
+	router.After(Wrap(func(w http.ResponseWriter, r *http.Request, p Params) {
+		p.AppSet("user", "test")
+	}))
+	router.GetHandler("/users/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := ParamsFromContext(r.Context())
+		w.Write([]byte(p.Get("id") + ":" + p.AppGet("user").(string)))
+	}))
+	router.Group("/admin", func(r *GroupRouter) {
+		r.GetHandler("/:id", http.HandlerFunc(paramsWriter))
+		r.PostHandler("/:id", http.HandlerFunc(paramsWriter))
+	}, traceAfter("admin"))
+	// a plain http middleware sees the params when ParamsInContext is set
+	router.ParamsInContext = true
+	router.After(WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Id", ParamsFromContext(r.Context()).Get("id"))
+	})))
+
+	res := serveHost(router, "GET", "", "/users/1")
+	assert.Equal(t, res.Body.String(), "1:test")
+	assert.Equal(t, res.Header().Get("X-Id"), "1")
+	assert.Equal(t, serveHost(router, "POST", "", "/admin/2").Body.String(), "POST:2")
+}
+
+func TestMountedHTTPHandler(t *testing.T) {
+	api := NewRouter()
+	api.GetHandler("/files/:name", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := ParamsFromContext(r.Context())
+		w.Write([]byte(p.Get("id") + ":" + p.Get("name")))
+	}))
+	router := NewRouter()
+	router.Mount("/users/:id", api)
+	assert.Equal(t, serveHost(router, "GET", "", "/users/1/files/a.txt").Body.String(), "1:a.txt")
+}
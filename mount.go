@@ -0,0 +1,100 @@
+package r2router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// mountParam is the catch-all name for the path below a mount prefix
+const mountParam = "mountpath"
+
+// mountPrefixKey is the request context key for the escaped path
+// stripped by Mount, used for redirects by a mounted Router
+type mountPrefixKey struct{}
+
+// mountHandler serves a mounted http.Handler
+type mountHandler struct {
+	handler    http.Handler
+	keepPrefix bool
+}
+
+func (m *mountHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, params Params) {
+	p, ok := params.(*params_)
+	if !ok {
+		p = &params_{}
+	}
+	rest := p.Get(mountParam)
+	ctx := context.WithValue(req.Context(), paramsKey{}, Params(p.clone(mountParam)))
+	u := req.URL
+	if !m.keepPrefix {
+		u = new(url.URL)
+		*u = *req.URL
+		u.Path = "/" + rest
+		u.RawPath = stripRawPath(req.URL.RawPath, u.Path)
+		if escaped := req.URL.EscapedPath(); strings.HasSuffix(escaped, u.EscapedPath()) {
+			prefix := mountPrefix(ctx) + strings.TrimSuffix(escaped, u.EscapedPath())
+			ctx = context.WithValue(ctx, mountPrefixKey{}, prefix)
+		}
+	}
+	req = req.WithContext(ctx)
+	req.URL = u
+	m.handler.ServeHTTP(w, req)
+}
+
+// mountPrefix returns the escaped path stripped by Mount, if any
+func mountPrefix(ctx context.Context) string {
+	prefix, _ := ctx.Value(mountPrefixKey{}).(string)
+	return prefix
+}
+
+// stripRawPath returns the end of rawPath which is the
+// escaped form of path, or empty if there is no such
+func stripRawPath(rawPath, path string) string {
+	for i := strings.LastIndexByte(rawPath, '/'); i >= 0; i = strings.LastIndexByte(rawPath[:i], '/') {
+		unescaped, err := url.PathUnescape(rawPath[i:])
+		if err != nil || len(unescaped) > len(path) {
+			return ""
+		}
+		if unescaped == path {
+			return rawPath[i:]
+		}
+	}
+	return ""
+}
+
+// Mount sends all requests below prefix, for any method, to
+// the handler with the prefix stripped from the path, such as
+// router.Mount("/static", http.FileServer(http.Dir("public"))).
+// The prefix can have params which the handler gets
+// using ParamsFromContext. It is matched with the routes of the
+// request method by specificity, as Any, so routes for paths below
+// the prefix are tried first and less specific ones such as
+// Get("/:a/:b") do not take paths below it.
+// The handler can be a Router or Seefor with its own middlewares,
+// NotFound and Timer, which run after those of this router.
+// Its handlers get the params and app data of this router too
+// and its redirects keep the prefix.
+// It panics as AddHandler
+func (r *Router) Mount(prefix string, handler http.Handler) *Route {
+	return r.mount(prefix, &mountHandler{handler: handler})
+}
+
+// MountKeepPrefix is as Mount but the handler
+// gets the path with the prefix
+func (r *Router) MountKeepPrefix(prefix string, handler http.Handler) *Route {
+	return r.mount(prefix, &mountHandler{handler: handler, keepPrefix: true})
+}
+
+func (r *Router) mount(prefix string, handler *mountHandler) *Route {
+	path := strings.TrimRight(prefix, "/") + "/*" + mountParam
+	if handler.handler == nil {
+		panic(&RouteError{Pattern: prefix, Err: fmt.Errorf("%w: handler can not be nil", ErrInvalidRoute)})
+	}
+	if strings.Contains(prefix, "*") {
+		panic(&RouteError{Pattern: prefix, Err: fmt.Errorf("%w: prefix can not have a catch-all", ErrInvalidRoute)})
+	}
+	return r.mustAdd([]string{HTTP_METHOD_ANY}, path, handler)[0]
+}
@@ -0,0 +1,220 @@
+package r2router
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func mountTestHandler(w http.ResponseWriter, req *http.Request) {
+	p := ParamsFromContext(req.Context())
+	w.Write([]byte(req.Method + " " + req.URL.Path + " " + req.URL.EscapedPath() + " tid=" + p.Get("tid")))
+}
+
+func TestRouterMount(t *testing.T) {
+	router := NewRouter()
+	router.Mount("/static/", http.HandlerFunc(mountTestHandler))
+	router.Mount("/tenants/:tid/files", http.HandlerFunc(mountTestHandler))
+	router.MountKeepPrefix("/debug/pprof", http.HandlerFunc(mountTestHandler))
+	router.Get("/static/version", func(w http.ResponseWriter, req *http.Request, p Params) {
+		w.Write([]byte("version"))
+	})
+
+	w := serveHost(router, "GET", "example.com", "/static/css/main.css")
+	assert.Equal(t, w.Body.String(), "GET /css/main.css /css/main.css tid=")
+	w = serveHost(router, "POST", "example.com", "/static/css/")
+	assert.Equal(t, w.Body.String(), "POST /css/ /css/ tid=")
+	w = serveHost(router, "GET", "example.com", "/static")
+	assert.Equal(t, w.Body.String(), "GET / / tid=")
+	w = serveHost(router, "GET", "example.com", "/static/")
+	assert.Equal(t, w.Body.String(), "GET / / tid=")
+	w = serveHost(router, "GET", "example.com", "/static/version")
+	assert.Equal(t, w.Body.String(), "version")
+
+	w = serveHost(router, "PUT", "example.com", "/tenants/acme/files/a%2Fb/c")
+	assert.Equal(t, w.Body.String(), "PUT /a/b/c /a%2Fb/c tid=acme")
+	w = serveHost(router, "PROPFIND", "example.com", "/tenants/acme/files/report%20v1.pdf")
+	assert.Equal(t, w.Body.String(), "PROPFIND /report v1.pdf /report%20v1.pdf tid=acme")
+
+	w = serveHost(router, "GET", "example.com", "/debug/pprof/heap")
+	assert.Equal(t, w.Body.String(), "GET /debug/pprof/heap /debug/pprof/heap tid=")
+
+	w = serveHost(router, "GET", "example.com", "/tenants/acme")
+	assert.Equal(t, w.Code, http.StatusNotFound)
+}
+
+func TestRouterMountSpecificity(t *testing.T) {
+	router := NewRouter()
+	router.Get("/:a/:b", func(w http.ResponseWriter, req *http.Request, p Params) {
+		w.Write([]byte("a=" + p.Get("a") + " b=" + p.Get("b")))
+	})
+	router.Mount("/static", http.HandlerFunc(mountTestHandler))
+
+	// the prefix is more specific than params
+	w := serveHost(router, "GET", "example.com", "/static/main.css")
+	assert.Equal(t, w.Body.String(), "GET /main.css /main.css tid=")
+	w = serveHost(router, "DELETE", "example.com", "/static/main.css")
+	assert.Equal(t, w.Body.String(), "DELETE /main.css /main.css tid=")
+	w = serveHost(router, "GET", "example.com", "/users/1")
+	assert.Equal(t, w.Body.String(), "a=users b=1")
+}
+
+func TestRouterMountFixedCase(t *testing.T) {
+	router := NewRouter()
+	router.RedirectFixedCase = true
+	router.Get("/users/:id", func(w http.ResponseWriter, req *http.Request, p Params) {})
+	router.Mount("/static", http.HandlerFunc(mountTestHandler))
+
+	// the Any tree of the mount has no match
+	w := serveHost(router, "GET", "example.com", "/USERS/1")
+	assert.Equal(t, w.Code, http.StatusMovedPermanently)
+	assert.Equal(t, w.Header().Get("Location"), "/users/1")
+	w = serveHost(router, "GET", "example.com", "/STATIC/main.css")
+	assert.Equal(t, w.Code, http.StatusMovedPermanently)
+	assert.Equal(t, w.Header().Get("Location"), "/static/main.css")
+}
+
+func TestRouterMountFileServer(t *testing.T) {
+	router := NewRouter()
+	router.Mount("/static", http.FileServer(http.Dir(".")))
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/static/LICENSE")
+	assert.Nil(t, err)
+	res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+
+	res, err = http.Get(ts.URL + "/static/missing.txt")
+	assert.Nil(t, err)
+	res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusNotFound)
+}
+
+func TestRouterMountRoutes(t *testing.T) {
+	router := NewRouter()
+	router.Mount("/debug", http.HandlerFunc(mountTestHandler))
+	routes := router.Routes()
+	assert.Equal(t, routes[0].Method, HTTP_METHOD_ANY)
+	assert.Equal(t, routes[0].Pattern, "/debug/*mountpath")
+	assert.Equal(t, routes[0].Handler, "github.com/vanng822/r2router.mountTestHandler")
+
+	assert.Panics(t, func() {
+		router.Mount("/debug", http.NotFoundHandler())
+	})
+	assert.Panics(t, func() {
+		router.Mount("/files/*path", http.NotFoundHandler())
+	})
+	assert.Panics(t, func() {
+		router.Mount("/nil", nil)
+	})
+}
+
+func TestSeeforMount(t *testing.T) {
+	seefor := NewSeeforRouter()
+	seefor.After(func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request, p Params) {
+			p.AppSet("user", "vanng822")
+			next.ServeHTTP(w, r, p)
+		})
+	})
+	seefor.Mount("/admin", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(ParamsFromContext(req.Context()).AppGet("user").(string) + " " + req.URL.Path))
+	}))
+	w := serveHost(seefor, "GET", "example.com", "/admin/users")
+	assert.Equal(t, w.Body.String(), "vanng822 /users")
+}
+
+func TestStripRawPath(t *testing.T) {
+	assert.Equal(t, stripRawPath("", "/a"), "")
+	assert.Equal(t, stripRawPath("/static/a%2Fb", "/a/b"), "/a%2Fb")
+	assert.Equal(t, stripRawPath("/static/a%2Fb/", "/a/b/"), "/a%2Fb/")
+	assert.Equal(t, stripRawPath("/files/a%2Fb/c", "/a/b/c"), "/a%2Fb/c")
+	assert.Equal(t, stripRawPath("/files/x/a%2Fb", "/a/c"), "")
+	assert.Equal(t, stripRawPath("/files/a%20b/", "/a b/"), "/a%20b/")
+	assert.Equal(t, stripRawPath("/files/", "/"), "/")
+}
+
+func TestSeeforSubRouter(t *testing.T) {
+	order := ""
+	record := func(name string) (Before, After) {
+		before := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order += name + ".before "
+				next.ServeHTTP(w, r)
+			})
+		}
+		after := func(next Handler) Handler {
+			return HandlerFunc(func(w http.ResponseWriter, r *http.Request, p Params) {
+				order += name + ".after "
+				p.AppSet(name, true)
+				next.ServeHTTP(w, r, p)
+			})
+		}
+		return before, after
+	}
+
+	parent := NewSeeforRouter()
+	before, after := record("parent")
+	parent.Before(before)
+	parent.After(after)
+	parent.Get("/", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("home"))
+	})
+
+	admin := NewSeeforRouter()
+	before, after = record("admin")
+	admin.Before(before)
+	admin.After(after)
+	admin.NotFound = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("admin not found " + r.URL.Path))
+	}
+	timer := admin.UseTimer(nil)
+	admin.StrictSlash = true
+	admin.RedirectTrailingSlash = true
+	admin.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(fmt.Sprintf("user:%s tid:%s parent:%v", p.Get("id"), p.Get("tid"), p.AppHas("parent"))))
+	})
+	parent.Mount("/tenants/:tid/admin", admin)
+
+	w := serveHost(parent, "GET", "example.com", "/tenants/acme/admin/users/1")
+	assert.Equal(t, w.Body.String(), "user:1 tid:acme parent:true")
+	assert.Equal(t, order, "parent.before parent.after admin.before admin.after ")
+	assert.Equal(t, timer.Get("/users/:id").Count, int64(1))
+
+	order = ""
+	w = serveHost(parent, "GET", "example.com", "/")
+	assert.Equal(t, w.Body.String(), "home")
+	assert.Equal(t, order, "parent.before parent.after ")
+
+	w = serveHost(parent, "GET", "example.com", "/tenants/acme/admin/groups")
+	assert.Equal(t, w.Code, http.StatusNotFound)
+	assert.Equal(t, w.Body.String(), "admin not found /groups")
+
+	w = serveHost(parent, "GET", "example.com", "/tenants/acme/admin/users/1/?q=a")
+	assert.Equal(t, w.Code, http.StatusMovedPermanently)
+	assert.Equal(t, w.Header().Get("Location"), "/tenants/acme/admin/users/1?q=a")
+}
+
+func TestRouterNestedMount(t *testing.T) {
+	v1 := NewRouter()
+	v1.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(p.Get("id") + " " + p.Get("tenant") + " " + p.Get("version")))
+	})
+	v1.RedirectFixedCase = true
+	api := NewRouter()
+	api.Mount("/:version", v1)
+	root := NewRouter()
+	root.Mount("/api", api)
+	hr := NewHostRouter()
+	hr.Host(":tenant.example.com", root)
+
+	w := serveHost(hr, "GET", "acme.example.com", "/api/v1/users/2")
+	assert.Equal(t, w.Body.String(), "2 acme v1")
+
+	w = serveHost(hr, "GET", "acme.example.com", "/api/v1/USERS/2")
+	assert.Equal(t, w.Header().Get("Location"), "/api/v1/users/2")
+}
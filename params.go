@@ -1,13 +1,21 @@
 package r2router
 
 import (
-
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Params is for parameters that are matched from URL.
 // It is also brigde to forward data from middleware.
 // An Example could be that a middleware to identify
-// the user API key, verify and get user data
+// the user API key, verify and get user data.
+// Params are reused once the request has been served
+// so they must not be kept after the handler returns,
+// see OrderedParams.Clone for a copy which can be kept
 type Params interface {
 	// Get returns param value for the given key
 	Get(key string) string
@@ -23,22 +31,200 @@ type Params interface {
 	AppHas(key interface{}) bool
 }
 
-// Holding value for named parameters
+// OrderedParams is implemented by the Params given to handlers
+// by the router, so they can be listed in order and copied
+//
+//	if op, ok := p.(r2router.OrderedParams); ok {
+//		keys := op.Keys()
+//	}
+type OrderedParams interface {
+	Params
+	// Keys returns the param names in path order,
+	// followed by those of a parent router or host
+	Keys() []string
+	// All returns the params in the order of Keys
+	All() []Param
+	// Len returns the number of params
+	Len() int
+	// Clone returns a copy which is not reused after the
+	// request, so it can be kept by goroutines which outlive it.
+	// It can not be changed, AppSet panics. App data values
+	// are shared with the request params
+	Clone() OrderedParams
+}
+
+// Param is a matched param, see OrderedParams.All
+type Param struct {
+	Key   string
+	Value string
+}
+
+// paramsKey is the request context key for Params
+type paramsKey struct{}
+
+// ParamsFromContext returns the params of the route for handlers
+// which only get the request, such as those added by GetHandler
+// or Mount, or any handler if ParamsInContext is set.
+// It returns empty params if there are none, never nil
+func ParamsFromContext(ctx context.Context) Params {
+	if params, ok := ctx.Value(paramsKey{}).(Params); ok {
+		return params
+	}
+	return &params_{}
+}
+
+// Holding value for named parameters.
+// Params are kept in path order in a small slice and appData
+// is not allocated until the application sets something
 type params_ struct {
-	requestParams map[string]string
+	requestParams []param
 	appData       map[interface{}]interface{}
+	// frozen is set for a Clone, which can not be changed
+	frozen bool
+}
+
+type param struct {
+	key   string
+	value string
+}
+
+var paramsPool = sync.Pool{
+	New: func() interface{} {
+		p := &params_{}
+		p.requestParams = make([]param, 0, 8)
+		return p
+	},
+}
+
+// acquireParams returns an empty params from the pool.
+// It must be given back using releaseParams when the
+// request has been served
+func acquireParams() *params_ {
+	return paramsPool.Get().(*params_)
+}
+
+func releaseParams(p *params_) {
+	p.reset()
+	paramsPool.Put(p)
+}
+
+func (p *params_) reset() {
+	p.requestParams = p.requestParams[:0]
+	for k := range p.appData {
+		delete(p.appData, k)
+	}
+}
+
+// push adds a matched param, nil params is for lookups
+// which only need to know if there is a handler
+func (p *params_) push(key, value string) {
+	if p != nil {
+		p.requestParams = append(p.requestParams, param{key, value})
+	}
+}
+
+// pop removes params added after n, used when backtracking
+func (p *params_) pop(n int) {
+	if p != nil {
+		p.requestParams = p.requestParams[:n]
+	}
+}
+
+// drop removes params from i to j, used when params
+// of a match are added after those of one not taken
+func (p *params_) drop(i, j int) {
+	if p != nil {
+		p.requestParams = append(p.requestParams[:i], p.requestParams[j:]...)
+	}
+}
+
+// clone returns a copy, which is not reused after the
+// request, without the param named skip
+func (p *params_) clone(skip string) *params_ {
+	c := &params_{}
+	c.requestParams = make([]param, 0, len(p.requestParams))
+	for _, param := range p.requestParams {
+		if param.key != skip {
+			c.requestParams = append(c.requestParams, param)
+		}
+	}
+	if len(p.appData) > 0 {
+		c.appData = make(map[interface{}]interface{}, len(p.appData))
+		for k, v := range p.appData {
+			c.appData[k] = v
+		}
+	}
+	return c
+}
+
+func (p *params_) Keys() []string {
+	keys := make([]string, len(p.requestParams))
+	for i := range p.requestParams {
+		keys[i] = p.requestParams[i].key
+	}
+	return keys
+}
+
+func (p *params_) All() []Param {
+	all := make([]Param, len(p.requestParams))
+	for i := range p.requestParams {
+		all[i] = Param{p.requestParams[i].key, p.requestParams[i].value}
+	}
+	return all
+}
+
+// Len is safe on nil params which are used for lookups
+func (p *params_) Len() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.requestParams)
+}
+
+func (p *params_) Clone() OrderedParams {
+	c := p.clone("")
+	c.frozen = true
+	return c
+}
+
+// unescape decodes percent-encoded values when
+// matching was done on the escaped path
+func (p *params_) unescape() {
+	for i := range p.requestParams {
+		if strings.IndexByte(p.requestParams[i].value, '%') == -1 {
+			continue
+		}
+		if value, err := url.PathUnescape(p.requestParams[i].value); err == nil {
+			p.requestParams[i].value = value
+		}
+	}
 }
 
 func (p *params_) Get(key string) string {
-	return p.requestParams[key]
+	for i := range p.requestParams {
+		if p.requestParams[i].key == key {
+			return p.requestParams[i].value
+		}
+	}
+	return ""
 }
 
 func (p *params_) Has(key string) bool {
-	_, exists := p.requestParams[key]
-	return exists
+	for i := range p.requestParams {
+		if p.requestParams[i].key == key {
+			return true
+		}
+	}
+	return false
 }
 
 func (p *params_) AppSet(key interface{}, val interface{}) {
+	if p.frozen {
+		panic("AppSet can not be used on cloned Params")
+	}
+	if p.appData == nil {
+		p.appData = make(map[interface{}]interface{})
+	}
 	p.appData[key] = val
 }
 
@@ -49,4 +235,92 @@ func (p *params_) AppGet(key interface{}) interface{} {
 func (p *params_) AppHas(key interface{}) bool {
 	_, exists := p.appData[key]
 	return exists
-}
\ No newline at end of file
+}
+
+// paramValue returns the param value or a *ParamError if it is missing
+func paramValue(p Params, key string) (string, error) {
+	if !p.Has(key) {
+		return "", &ParamError{Name: key, Err: ErrMissingParam}
+	}
+	return p.Get(key), nil
+}
+
+// GetInt returns the param value as int. The typed getters
+// return a *ParamError if the param is missing or invalid
+func GetInt(p Params, key string) (int, error) {
+	value, err := paramValue(p, key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(value, 10, 0)
+	if err != nil {
+		return 0, invalidParam(key, value, "int")
+	}
+	return int(n), nil
+}
+
+// GetInt64 returns the param value as int64
+func GetInt64(p Params, key string) (int64, error) {
+	value, err := paramValue(p, key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, invalidParam(key, value, "int64")
+	}
+	return n, nil
+}
+
+// GetUint returns the param value as uint
+func GetUint(p Params, key string) (uint, error) {
+	value, err := paramValue(p, key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return 0, invalidParam(key, value, "uint")
+	}
+	return uint(n), nil
+}
+
+// GetBool returns the param value as bool,
+// accepted values are those of strconv.ParseBool
+func GetBool(p Params, key string) (bool, error) {
+	value, err := paramValue(p, key)
+	if err != nil {
+		return false, err
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, invalidParam(key, value, "bool")
+	}
+	return b, nil
+}
+
+// GetUUID returns the param value in the canonical
+// 8-4-4-4-12 hex form, lowercased
+func GetUUID(p Params, key string) (string, error) {
+	value, err := paramValue(p, key)
+	if err != nil {
+		return "", err
+	}
+	if !isUUID(value) {
+		return "", invalidParam(key, value, "uuid")
+	}
+	return strings.ToLower(value), nil
+}
+
+// GetTime returns the param value parsed using the layout
+func GetTime(p Params, key, layout string) (time.Time, error) {
+	value, err := paramValue(p, key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, invalidParam(key, value, "time in layout "+layout)
+	}
+	return t, nil
+}
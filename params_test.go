@@ -1,8 +1,12 @@
 package r2router
 
 import (
+	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"net/http"
 	"testing"
+	"time"
 )
 
 func TestParamsAppData(t *testing.T) {
@@ -27,8 +31,7 @@ func TestParamsAppData(t *testing.T) {
 
 func TestParamsRequestData(t *testing.T) {
 	p := params_{}
-	p.requestParams = make(map[string]string)
-	p.requestParams["hello"] = "World"
+	p.requestParams = []param{{"hello", "World"}}
 	
 	assert.True(t, p.Has("hello"))
 	assert.False(t, p.Has("world"))
@@ -36,3 +39,162 @@ func TestParamsRequestData(t *testing.T) {
 	assert.Equal(t, p.Get("hello"), "World")
 }
 
+
+func TestParamsPool(t *testing.T) {
+	p := acquireParams()
+	p.push("id", "1")
+	p.push("name", "vanng822")
+	p.AppSet("user", "CPO")
+	assert.Equal(t, p.Get("name"), "vanng822")
+	p.pop(1)
+	assert.False(t, p.Has("name"))
+	assert.Equal(t, p.Get("id"), "1")
+
+	releaseParams(p)
+	assert.Equal(t, len(p.requestParams), 0)
+	assert.False(t, p.AppHas("user"))
+}
+
+func TestParamsNil(t *testing.T) {
+	var p *params_
+	// lookups without params must not panic
+	p.push("id", "1")
+	p.pop(0)
+	assert.Equal(t, p.Len(), 0)
+}
+
+func TestParamsAppDataLazy(t *testing.T) {
+	p := params_{}
+	assert.Nil(t, p.AppGet("hello"))
+	assert.False(t, p.AppHas("hello"))
+	p.AppSet("hello", "World")
+	assert.Equal(t, p.AppGet("hello"), "World")
+}
+
+func TestParamsUnescape(t *testing.T) {
+	p := params_{}
+	p.requestParams = []param{{"id", "a%2Fb"}, {"name", "a%20b"}, {"plain", "ab"}, {"broken", "a%zz"}}
+	p.unescape()
+	assert.Equal(t, p.Get("id"), "a/b")
+	assert.Equal(t, p.Get("name"), "a b")
+	assert.Equal(t, p.Get("plain"), "ab")
+	assert.Equal(t, p.Get("broken"), "a%zz")
+}
+
+func TestParamsFromContext(t *testing.T) {
+	p := ParamsFromContext(context.Background())
+	assert.NotNil(t, p)
+	assert.False(t, p.Has("id"))
+
+	params := &params_{}
+	params.push("id", "1")
+	ctx := context.WithValue(context.Background(), paramsKey{}, Params(params))
+	assert.Equal(t, ParamsFromContext(ctx).Get("id"), "1")
+}
+
+func TestParamsClone(t *testing.T) {
+	p := acquireParams()
+	p.push("id", "1")
+	p.push("rest", "a/b")
+	p.AppSet("user", "vanng822")
+	c := p.clone("rest")
+	releaseParams(p)
+	assert.Equal(t, c.requestParams, []param{{"id", "1"}})
+	assert.Equal(t, c.AppGet("user"), "vanng822")
+}
+
+func TestParamsTyped(t *testing.T) {
+	p := &params_{}
+	p.push("id", "-42")
+	p.push("count", "7")
+	p.push("big", "9223372036854775807")
+	p.push("active", "true")
+	p.push("oid", "6BA7B810-9DAD-11D1-80B4-00C04FD430C8")
+	p.push("day", "2015-05-01")
+	p.push("bad", "abc")
+
+	i, err := GetInt(p, "id")
+	assert.Nil(t, err)
+	assert.Equal(t, i, -42)
+	i64, err := GetInt64(p, "big")
+	assert.Nil(t, err)
+	assert.Equal(t, i64, int64(9223372036854775807))
+	u, err := GetUint(p, "count")
+	assert.Nil(t, err)
+	assert.Equal(t, u, uint(7))
+	b, err := GetBool(p, "active")
+	assert.Nil(t, err)
+	assert.True(t, b)
+	oid, err := GetUUID(p, "oid")
+	assert.Nil(t, err)
+	assert.Equal(t, oid, "6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	day, err := GetTime(p, "day", "2006-01-02")
+	assert.Nil(t, err)
+	assert.Equal(t, day, time.Date(2015, 5, 1, 0, 0, 0, 0, time.UTC))
+
+	_, err = GetUint(p, "id")
+	assert.True(t, errors.Is(err, ErrInvalidParam))
+	assert.Equal(t, err.Error(), `param id: invalid param: "-42" is not a valid uint`)
+	_, err = GetInt(p, "bad")
+	assert.True(t, errors.Is(err, ErrInvalidParam))
+	_, err = GetInt64(p, "bad")
+	assert.Equal(t, err.Error(), `param bad: invalid param: "abc" is not a valid int64`)
+	_, err = GetBool(p, "bad")
+	assert.True(t, errors.Is(err, ErrInvalidParam))
+	_, err = GetUUID(p, "bad")
+	assert.True(t, errors.Is(err, ErrInvalidParam))
+	_, err = GetTime(p, "bad", "2006-01-02")
+	assert.Equal(t, err.Error(), `param bad: invalid param: "abc" is not a valid time in layout 2006-01-02`)
+
+	_, err = GetInt(p, "missing")
+	assert.True(t, errors.Is(err, ErrMissingParam))
+	perr, ok := err.(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, perr.Name, "missing")
+	assert.Equal(t, err.Error(), "param missing: missing param")
+}
+
+func TestParamsOrdered(t *testing.T) {
+	p := &params_{}
+	assert.Equal(t, p.Keys(), []string{})
+	assert.Equal(t, p.All(), []Param{})
+	p.push("user", "vanng822")
+	p.push("repo", "r2router")
+	p.push("id", "1")
+
+	assert.Equal(t, p.Len(), 3)
+	assert.Equal(t, p.Keys(), []string{"user", "repo", "id"})
+	assert.Equal(t, p.All(), []Param{{"user", "vanng822"}, {"repo", "r2router"}, {"id", "1"}})
+}
+
+func TestParamsCloneSnapshot(t *testing.T) {
+	p := acquireParams()
+	p.push("id", "1")
+	p.AppSet("user", "Tester")
+
+	c := p.Clone()
+	releaseParams(p)
+	p = acquireParams()
+	p.push("id", "2")
+
+	assert.Equal(t, c.Len(), 1)
+	assert.Equal(t, c.Get("id"), "1")
+	assert.Equal(t, c.AppGet("user"), "Tester")
+	assert.Panics(t, func() {
+		c.AppSet("user", "Other")
+	})
+	assert.Equal(t, c.Clone().All(), []Param{{"id", "1"}})
+	releaseParams(p)
+}
+
+func TestParamsRouteOrder(t *testing.T) {
+	router := NewRouter()
+	var keys []string
+	router.Get("/repos/:owner/:repo/issues/:number", func(w http.ResponseWriter, r *http.Request, p Params) {
+		keys = p.(OrderedParams).Keys()
+	})
+	host := NewHostRouter()
+	host.Host(":tenant.example.com", router)
+	serveHost(host, "GET", "acme.example.com", "/repos/vanng822/r2router/issues/1")
+	assert.Equal(t, keys, []string{"owner", "repo", "number", "tenant"})
+}
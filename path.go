@@ -0,0 +1,48 @@
+package r2router
+
+import (
+	"path"
+)
+
+// needsClean reports whether p has empty, . or .. segments
+// or does not start with a slash
+func needsClean(p string) bool {
+	if p == "" || p[0] != '/' {
+		return true
+	}
+	for i := 1; i < len(p); i++ {
+		if p[i-1] != '/' {
+			continue
+		}
+		switch {
+		case p[i] == '/':
+			return true
+		case p[i] == '.':
+			// . or .. as a whole segment
+			j := i + 1
+			if j < len(p) && p[j] == '.' {
+				j++
+			}
+			if j == len(p) || p[j] == '/' {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cleanPath returns the canonical form of p as path.Clean
+// but it keeps a trailing slash
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	cleaned := path.Clean(p)
+	if p[len(p)-1] == '/' && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
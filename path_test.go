@@ -0,0 +1,25 @@
+package r2router
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNeedsClean(t *testing.T) {
+	for _, p := range []string{"/", "/users", "/users/", "/users/.hidden", "/users/..x/a", "/a.b/c"} {
+		assert.False(t, needsClean(p), p)
+	}
+	for _, p := range []string{"", "users", "//users", "/users//keys", "/users/./keys", "/users/../keys", "/users/.", "/users/.."} {
+		assert.True(t, needsClean(p), p)
+	}
+}
+
+func TestCleanPath(t *testing.T) {
+	assert.Equal(t, cleanPath(""), "/")
+	assert.Equal(t, cleanPath("users"), "/users")
+	assert.Equal(t, cleanPath("//users//keys"), "/users/keys")
+	assert.Equal(t, cleanPath("/users/./keys/"), "/users/keys/")
+	assert.Equal(t, cleanPath("/users/vanng822/../keys"), "/users/keys")
+	assert.Equal(t, cleanPath("/../.."), "/")
+	assert.Equal(t, cleanPath("/users/.."), "/")
+}
@@ -16,7 +16,11 @@ type RouteManager interface {
 	// Register a route and return the path
 	// This can be good for adding and register handler at the same time
 	// router.Get(rm.Add("user", "/user/:id"), handler)
+	// Will panic if the name is used or the path is invalid
 	Add(routeName, path string) string
+	// Register a route, as Add, but returns a *RouteError
+	// if the name is used or the path is invalid
+	Register(routeName, path string) error
 	// Return the path for a specific route name
 	// Use for register handler
 	// router.Delete(rm.PathFor("user"), handler)
@@ -25,6 +29,7 @@ type RouteManager interface {
 	// Will panic if missmatched
 	UrlFor(routeName string, params map[string][]string) string
 	// Returning url for given path and provided data
+	// Param values are escaped, a catch-all value keeps its slashes
 	// Will panic if missmatched
 	UrlForPath(path string, params map[string][]string) string
 }
@@ -46,12 +51,21 @@ func (m *routeManager) SetBaseUrl(baseUrl string) {
 }
 
 func (m *routeManager) Add(routeName, path string) string {
-	if _, exist := m.routes[routeName]; exist {
-		panic("Route name must be unique")
+	if err := m.Register(routeName, path); err != nil {
+		panic(err)
 	}
+	return path
+}
 
+func (m *routeManager) Register(routeName, path string) error {
+	if existing, exist := m.routes[routeName]; exist {
+		return &RouteError{Pattern: path, Existing: existing, Err: fmt.Errorf("%w %s", ErrDuplicateRouteName, routeName)}
+	}
+	if err := newRouteTree().tryAddRoute(path, nil); err != nil {
+		return err
+	}
 	m.routes[routeName] = path
-	return path
+	return nil
 }
 
 func (m *routeManager) PathFor(routeName string) string {
@@ -66,7 +80,7 @@ func (m *routeManager) UrlFor(routeName string, params map[string][]string) stri
 }
 
 func (m *routeManager) UrlForPath(path string, params map[string][]string) string {
-	paths := strings.Split(path, "/")
+	paths := splitPattern(path)
 	parts := make([]string, 0)
 
 	urlParams := url.Values{}
@@ -77,21 +91,51 @@ func (m *routeManager) UrlForPath(path string, params map[string][]string) strin
 	}
 
 	for _, p := range paths {
-		if p == "" || p[:1] != ":" {
+		if p == "" || (p[:1] != "*" && strings.IndexByte(p, ':') == -1) {
 			parts = append(parts, p)
 			continue
 		}
-		key := p[1:]
-		if val, exist := params[key]; exist {
-			if len(val) == 1 {
-				parts = append(parts, val[0])
+		if p[:1] == "*" {
+			key := p[1:]
+			if val, exist := params[key]; exist && len(val) == 1 {
+				// catch-all value may contain slashes
+				parts = append(parts, escapeCatchAll(strings.TrimLeft(val[0], "/")))
 				urlParams.Del(key)
 				continue
 			}
+			panic(fmt.Sprintf("Param %s missing in provided data or has multiple values", key))
+		}
+		segmentParts, err := parseSegment(p)
+		if err != nil {
+			panic(err)
+		}
+		segment := ""
+		for _, part := range segmentParts {
+			if !part.param {
+				segment += part.text
+				continue
+			}
+			key := part.text
+			val, exist := params[key]
+			if !exist && part.optional {
+				// the segment is left out
+				segment = ""
+				break
+			}
+			if !exist || len(val) != 1 {
+				panic(fmt.Sprintf("Param %s missing in provided data or has multiple values", key))
+			}
+			if part.matcher != nil && !part.matcher(val[0]) {
+				panic(fmt.Sprintf("Param %s value %s does not satisfy %s", key, val[0], p))
+			}
+			segment += url.PathEscape(val[0])
+			urlParams.Del(key)
+		}
+		if segment != "" {
+			parts = append(parts, segment)
 		}
-
-		panic(fmt.Sprintf("Param %s missing in provided data or has multiple values", key))
 	}
+
 	var query string
 	if len(urlParams) > 0 {
 		query = fmt.Sprintf("?%s", urlParams.Encode())
@@ -100,3 +144,13 @@ func (m *routeManager) UrlForPath(path string, params map[string][]string) strin
 	return fmt.Sprintf("%s%s%s", m.baseUrl, strings.Join(parts, "/"), query)
 	
 }
+
+// escapeCatchAll escapes each segment of a catch-all value
+// and keeps the slashes between them
+func escapeCatchAll(value string) string {
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
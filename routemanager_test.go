@@ -1,6 +1,7 @@
 package r2router
 
 import (
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -46,3 +47,84 @@ func TestPathForMissing(t *testing.T) {
 		m.PathFor("same::too")
 	})
 }
+
+func TestUrlForWildcard(t *testing.T) {
+	m := NewRouteManager()
+	m.Add("static", "/static/*filepath")
+
+	assert.Equal(t, m.UrlFor("static", P{"filepath": []string{"css/main.css"}}), "/static/css/main.css")
+	assert.Equal(t, m.UrlFor("static", P{"filepath": []string{"/css/main.css"}, "v": []string{"2"}}), "/static/css/main.css?v=2")
+	assert.Panics(t, func() {
+		m.UrlFor("static", P{})
+	})
+}
+
+func TestUrlForConstrained(t *testing.T) {
+	m := NewRouteManager()
+	m.Add("user", `/users/:id(\d+)/orders/:oid<uuid>`)
+
+	assert.Equal(t, m.UrlFor("user", P{"id": []string{"42"}, "oid": []string{"0e8b4f0a-9a3c-4c5d-8f7e-1a2b3c4d5e6f"}}), "/users/42/orders/0e8b4f0a-9a3c-4c5d-8f7e-1a2b3c4d5e6f")
+	assert.Panics(t, func() {
+		m.UrlFor("user", P{"id": []string{"abc"}, "oid": []string{"0e8b4f0a-9a3c-4c5d-8f7e-1a2b3c4d5e6f"}})
+	})
+}
+
+func TestUrlForMixedSegments(t *testing.T) {
+	m := NewRouteManager()
+	m.Add("file", `/v:version/files/:name.:ext(pdf|txt)`)
+
+	assert.Equal(t, m.UrlFor("file", P{"version": []string{"2"}, "name": []string{"report"}, "ext": []string{"pdf"}}), "/v2/files/report.pdf")
+	assert.Panics(t, func() {
+		m.UrlFor("file", P{"version": []string{"2"}, "name": []string{"report"}, "ext": []string{"doc"}})
+	})
+	assert.Panics(t, func() {
+		m.UrlFor("file", P{"version": []string{"2"}, "name": []string{"report"}})
+	})
+}
+
+func TestUrlForOptional(t *testing.T) {
+	m := NewRouteManager()
+	m.Add("docs", "/docs/:lang?=en/:page")
+	m.Add("reports", "/reports/:year/:month?")
+
+	assert.Equal(t, m.UrlFor("docs", P{"lang": []string{"sv"}, "page": []string{"intro"}}), "/docs/sv/intro")
+	assert.Equal(t, m.UrlFor("docs", P{"page": []string{"intro"}}), "/docs/intro")
+	assert.Equal(t, m.UrlFor("reports", P{"year": []string{"2015"}, "month": []string{"05"}}), "/reports/2015/05")
+	assert.Equal(t, m.UrlFor("reports", P{"year": []string{"2015"}}), "/reports/2015")
+	assert.Panics(t, func() {
+		m.UrlFor("reports", P{"month": []string{"05"}})
+	})
+}
+
+func TestUrlForEscaped(t *testing.T) {
+	m := NewRouteManager()
+	m.Add("file", "/files/:id/:name.:ext")
+	m.Add("static", "/static/*filepath")
+
+	assert.Equal(t, m.UrlFor("file", P{"id": []string{"a/b"}, "name": []string{"my report"}, "ext": []string{"pdf"}}), "/files/a%2Fb/my%20report.pdf")
+	assert.Equal(t, m.UrlFor("static", P{"filepath": []string{"my docs/a?b.txt"}}), "/static/my%20docs/a%3Fb.txt")
+}
+
+func TestRegister(t *testing.T) {
+	m := NewRouteManager()
+	assert.Nil(t, m.Register("user", "/users/:id"))
+
+	err := m.Register("user", "/users/:uid")
+	assert.True(t, errors.Is(err, ErrDuplicateRouteName))
+	assert.Equal(t, err.Error(), "/users/:uid: duplicate route name user with /users/:id")
+
+	err = m.Register("broken", "/users/:id(\\d+")
+	assert.True(t, errors.Is(err, ErrInvalidRoute))
+	assert.Panics(t, func() {
+		m.PathFor("broken")
+	})
+	assert.Panics(t, func() {
+		m.Add("broken", "/users/:/keys")
+	})
+}
+
+func TestUrlForBracePatterns(t *testing.T) {
+	m := NewRouteManager()
+	m.Add("file", "/users/{id}/files/{filepath...}")
+	assert.Equal(t, m.UrlFor("file", P{"id": []string{"1"}, "filepath": []string{"a/b.txt"}}), "/users/1/files/a/b.txt")
+}
@@ -1,9 +1,14 @@
 package r2router
 
 import (
+	"fmt"
 	//"log"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	//"time"
 )
 
@@ -15,6 +20,8 @@ const (
 	HTTP_METHOD_HEAD    = "HEAD"
 	HTTP_METHOD_PUT     = "PUT"
 	HTTP_METHOD_PATCH   = "PATCH"
+	// HTTP_METHOD_ANY is the method of routes added by Any
+	HTTP_METHOD_ANY = "*"
 )
 
 type Handler interface {
@@ -29,67 +36,310 @@ func (h HandlerFunc) ServeHTTP(w http.ResponseWriter, req *http.Request, params
 }
 
 type Router struct {
-	roots                  map[string]*rootNode
+	// roots holds the method trees, map[string]*rootNode.
+	// Stored trees are never changed, adding or removing
+	// a route copies the nodes it changes and swaps in
+	// new trees sharing the other nodes
+	roots atomic.Value
+	// gen is the generation of the last tree update
+	gen uint64
+	// mu serializes changes of registry, roots and gen
+	mu                     sync.Mutex
+	registry               []*Route
 	HandleMethodNotAllowed bool
 	MethodNotAllowed       http.HandlerFunc
 	NotFound               http.HandlerFunc
+	// StrictSlash makes /users and /users/ different paths,
+	// a request only matches if it has the trailing slash
+	// the route was registered with. Routes added meanwhile
+	// can be registered for both
+	StrictSlash bool
+	// RedirectTrailingSlash redirects to the registered form
+	// in strict slash mode if the path only differs by a trailing slash
+	RedirectTrailingSlash bool
+	// CleanPath removes empty, . and .. segments before matching,
+	// escaped slashes and dots are not taken as such
+	CleanPath bool
+	// RedirectCleanPath redirects to the cleaned path
+	// instead of serving it when CleanPath is set
+	RedirectCleanPath bool
+	// CaseInsensitive matches static segments regardless of case
+	// if there is no exact match. Param values are kept as they are
+	CaseInsensitive bool
+	// RedirectFixedCase redirects to the path with static segments
+	// in the case they were registered if only the case differs
+	RedirectFixedCase bool
+	// UseEscapedPath matches on the escaped path, URL.EscapedPath(),
+	// so an encoded slash does not split a segment. Each param value
+	// is decoded on its own. Static segments and param constraints
+	// are matched against the escaped form
+	UseEscapedPath bool
+	// HandleHEAD serves HEAD requests through GET routes
+	// if there is no HEAD route, net/http discards the body
+	HandleHEAD bool
+	// SendAllowHeader sets the Allow header on 405 responses
+	SendAllowHeader bool
+	// HandleOptionsAsterisk answers OPTIONS * with the methods
+	// of all routes. Note that http.Server answers it itself
+	// unless DisableGeneralOptionsHandler is set
+	HandleOptionsAsterisk bool
+	// Options handles OPTIONS requests without a route if set,
+	// the Allow header is set before it is called
+	Options http.HandlerFunc
+	// SetPathValues sets each param with Request.SetPathValue
+	// so handlers can use Request.PathValue. It costs allocations
+	// for the values on each request
+	SetPathValues bool
+	// ParamsInContext puts the params in the request context for
+	// all routes, so ParamsFromContext works in any handler or
+	// middleware. It costs an allocation per request. Routes added
+	// with GetHandler and the like always have them in the context
+	ParamsInContext bool
 }
 
 // NewRouter return a new Router
 func NewRouter() *Router {
 	r := &Router{}
-	r.roots = make(map[string]*rootNode)
 	r.HandleMethodNotAllowed = true
 	return r
 }
 
+// EnableMethodCompliance sets HandleHEAD, SendAllowHeader and
+// HandleOptionsAsterisk so HEAD, 405 and OPTIONS are as RFC 9110
+// describes. Each can be unset afterwards
+func (r *Router) EnableMethodCompliance() {
+	r.HandleHEAD = true
+	r.SendAllowHeader = true
+	r.HandleOptionsAsterisk = true
+}
+
 // http Handler Interface
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	//now := time.Now()
-	if root, exist := r.roots[req.Method]; exist {
-		handler, params, _ := root.match(req.URL.Path)
-		if handler != nil {
-			handler.ServeHTTP(w, req, params)
-			//log.Println(time.Now().Sub(now))
-			return
+	path, ok := r.routingPath(w, req)
+	if !ok {
+		return
+	}
+	params := acquireParams()
+	if route := r.lookup(req.Method, path, params, true); route != nil {
+		req = r.completeParams(req, params)
+		route.handler.ServeHTTP(w, req, params)
+		releaseParams(params)
+		//log.Println(time.Now().Sub(now))
+		return
+	}
+	releaseParams(params)
+	r.handleMissing(w, req, path)
+}
+
+// routingPath returns the path to match for the request, the escaped
+// one if UseEscapedPath is set. It is cleaned if CleanPath is set,
+// the escaped path is cleaned so an escaped slash or dot in a segment
+// is kept. If RedirectCleanPath is set too it redirects instead and
+// returns false. It answers OPTIONS * if HandleOptionsAsterisk is set
+// and returns false
+func (r *Router) routingPath(w http.ResponseWriter, req *http.Request) (string, bool) {
+	path := req.URL.Path
+	if r.HandleOptionsAsterisk && path == "*" && req.Method == HTTP_METHOD_OPTIONS {
+		r.serveOptions(w, req, r.allowedMethods(path))
+		return path, false
+	}
+	if !r.UseEscapedPath && !r.CleanPath {
+		return path, true
+	}
+	escaped := req.URL.EscapedPath()
+	if r.CleanPath && needsClean(escaped) {
+		escaped = cleanPath(escaped)
+		if r.RedirectCleanPath {
+			r.redirect(w, req, escaped)
+			return escaped, false
+		}
+		if !r.UseEscapedPath {
+			if unescaped, err := url.PathUnescape(escaped); err == nil {
+				return unescaped, true
+			}
 		}
 	}
-	r.handleMissing(w, req)
+	if r.UseEscapedPath {
+		return escaped, true
+	}
+	return path, true
 }
 
-func (r *Router) handleMissing(w http.ResponseWriter, req *http.Request) {
-	// if options find handler for different method
-	if req.Method == HTTP_METHOD_OPTIONS {
-		// build and serve options
-		availableMethods := make([]string, 0, len(r.roots))
-		for method := range r.roots {
-			if root, exist := r.roots[method]; exist {
-				handler, _, _ := root.match(req.URL.Path)
-				if handler != nil {
-					availableMethods = append(availableMethods, method)
-				}
+// completeParams decodes param values if UseEscapedPath is set
+// and adds params and app data of the parent router if mounted,
+// otherwise params matched from the host by HostRouter.
+// Own params come first so they win if names are the same.
+// The params are set as path values if SetPathValues is set.
+// It returns the request with the params in its context
+// if ParamsInContext is set
+func (r *Router) completeParams(req *http.Request, params *params_) *http.Request {
+	if r.UseEscapedPath {
+		params.unescape()
+	}
+	ctx := req.Context()
+	if parent, ok := ctx.Value(paramsKey{}).(*params_); ok {
+		// parent has the host params already
+		params.requestParams = append(params.requestParams, parent.requestParams...)
+		for k, v := range parent.appData {
+			if !params.AppHas(k) {
+				params.AppSet(k, v)
 			}
 		}
-		if len(availableMethods) > 0 {
-			w.Header().Add("Allow", strings.Join(availableMethods, ", "))
-			w.WriteHeader(http.StatusOK)
+	} else if hostParams, ok := ctx.Value(hostParamsKey{}).([]param); ok {
+		params.requestParams = append(params.requestParams, hostParams...)
+	}
+	if r.SetPathValues {
+		// backwards so the first of a name wins as for Get
+		for i := len(params.requestParams) - 1; i >= 0; i-- {
+			req.SetPathValue(params.requestParams[i].key, params.requestParams[i].value)
+		}
+	}
+	if r.ParamsInContext {
+		return withParams(req, params)
+	}
+	return req
+}
+
+// lookup returns the route for method and path, nil if none.
+// A case-insensitive lookup is done if CaseInsensitive is set
+// and there is no exact match
+func (r *Router) lookup(method, path string, params *params_, withAny bool) *leaf {
+	route := r.findMethod(method, path, params, nil, withAny)
+	if route == nil && r.CaseInsensitive {
+		route = r.findMethod(method, path, params, &foldState{}, withAny)
+	}
+	return route
+}
+
+// findMethod returns the route for method and path, or one added
+// by Any if withAny is set. If HandleHEAD is set HEAD routes are
+// tried first for HEAD and then GET routes as for GET
+func (r *Router) findMethod(method, path string, params *params_, fold *foldState, withAny bool) *leaf {
+	if method == HTTP_METHOD_HEAD && r.HandleHEAD {
+		if route := r.find(method, path, params, fold, false); route != nil {
+			return route
+		}
+		method = HTTP_METHOD_GET
+	}
+	return r.find(method, path, params, fold, withAny)
+}
+
+// find returns the route for method and path in the method tree.
+// If withAny is set a route added by Any is taken instead if it
+// is more specific, so a static route added by Any wins over
+// a param or catch-all of the method and the other way around.
+// In strict slash mode the trailing slash must be as registered,
+// except for catch-all routes which take any path
+func (r *Router) find(method, path string, params *params_, fold *foldState, withAny bool) *leaf {
+	roots := r.trees()
+	var flags matchFlags
+	if r.StrictSlash {
+		flags = matchStrict
+	}
+	start := params.Len()
+	var route *leaf
+	if root, exist := roots[method]; exist {
+		route = root.match(path, params, fold, flags)
+	}
+	anyRoot, exist := roots[HTTP_METHOD_ANY]
+	if !withAny || !exist {
+		return route
+	}
+	mark := params.Len()
+	var fixed []byte
+	if fold != nil {
+		fixed = fold.path
+	}
+	other := anyRoot.match(path, params, fold, flags)
+	if other == nil || (route != nil && route.rank <= other.rank) {
+		params.pop(mark)
+		if fold != nil {
+			// the fixed path is of the route taken
+			fold.path = fixed
+		}
+		return route
+	}
+	params.drop(start, mark)
+	return other
+}
+
+// fixedCasePath returns the path with static segments
+// in the case they were registered if there is such route
+func (r *Router) fixedCasePath(method, path string) (string, bool) {
+	fold := &foldState{fix: true}
+	if r.findMethod(method, path, nil, fold, true) == nil || fold.path == nil {
+		return "", false
+	}
+	fixedPath := "/" + string(fold.path)
+	return fixedPath, fixedPath != path
+}
+
+// escapedPath returns path, which is as matched, in escaped form
+func (r *Router) escapedPath(path string) string {
+	if r.UseEscapedPath {
+		return path
+	}
+	u := url.URL{Path: path}
+	return u.EscapedPath()
+}
+
+// redirect sends the client to the canonical path, which is escaped.
+// It is 301 for GET and HEAD and 308 otherwise so method and body
+// are kept
+func (r *Router) redirect(w http.ResponseWriter, req *http.Request, path string) {
+	code := http.StatusMovedPermanently
+	if req.Method != HTTP_METHOD_GET && req.Method != HTTP_METHOD_HEAD {
+		code = http.StatusPermanentRedirect
+	}
+	// a target starting with // or /\ is taken by browsers as
+	// another host, so keep a single slash to stay on this one
+	path = "/" + strings.TrimLeft(mountPrefix(req.Context())+path, "/\\")
+	u := url.URL{Path: path, RawQuery: req.URL.RawQuery}
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		// String escapes Path again if RawPath is not valid
+		u.Path, u.RawPath = unescaped, path
+	}
+	http.Redirect(w, req, u.String(), code)
+}
+
+func (r *Router) handleMissing(w http.ResponseWriter, req *http.Request, path string) {
+	if r.StrictSlash && r.RedirectTrailingSlash && len(path) > 1 {
+		fixedPath := path + "/"
+		if path[len(path)-1] == '/' {
+			fixedPath = path[:len(path)-1]
+		}
+		if r.lookup(req.Method, fixedPath, nil, true) != nil {
+			r.redirect(w, req, r.escapedPath(fixedPath))
+			return
+		}
+	}
+
+	if r.RedirectFixedCase {
+		if fixedPath, found := r.fixedCasePath(req.Method, path); found {
+			r.redirect(w, req, r.escapedPath(fixedPath))
+			return
+		}
+	}
+
+	if req.Method == HTTP_METHOD_OPTIONS {
+		if allow := r.allowedMethods(path); len(allow) > 0 {
+			r.serveOptions(w, req, allow)
 			return
 		}
 	}
 
 	if r.HandleMethodNotAllowed {
-		for method := range r.roots {
-			if root, exist := r.roots[method]; exist {
-				handler, _, _ := root.match(req.URL.Path)
-				if handler != nil {
-					if r.MethodNotAllowed != nil {
-						r.MethodNotAllowed(w, req)
-					} else {
-						http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
-					}
-					return
-				}
+		if allow := r.allowedMethods(path); len(allow) > 0 {
+			if r.SendAllowHeader {
+				w.Header().Set("Allow", strings.Join(allow, ", "))
 			}
+			if r.MethodNotAllowed != nil {
+				r.MethodNotAllowed(w, req)
+			} else {
+				http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			}
+			return
 		}
 	}
 
@@ -100,35 +350,282 @@ func (r *Router) handleMissing(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
-func (r *Router) Get(path string, handler HandlerFunc) {
-	r.AddHandler(HTTP_METHOD_GET, path, handler)
+// allowedMethods returns the methods with a route for path in order,
+// for * the methods of all routes. HEAD is included with GET
+// if HandleHEAD is set. Routes added by Any are not included
+// since they have no match when this is needed
+func (r *Router) allowedMethods(path string) []string {
+	roots := r.trees()
+	allow := make([]string, 0, len(roots)+1)
+	get, head := false, false
+	for method := range roots {
+		if method == HTTP_METHOD_ANY || (path != "*" && r.lookup(method, path, nil, false) == nil) {
+			continue
+		}
+		allow = append(allow, method)
+		get = get || method == HTTP_METHOD_GET
+		head = head || method == HTTP_METHOD_HEAD
+	}
+	if get && !head && r.HandleHEAD {
+		allow = append(allow, HTTP_METHOD_HEAD)
+	}
+	sort.Strings(allow)
+	return allow
+}
+
+// serveOptions answers an OPTIONS request without a route
+func (r *Router) serveOptions(w http.ResponseWriter, req *http.Request, allow []string) {
+	w.Header().Set("Allow", strings.Join(allow, ", "))
+	if r.Options != nil {
+		r.Options(w, req)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *Router) Get(path string, handler HandlerFunc) *Route {
+	return r.AddHandler(HTTP_METHOD_GET, path, handler)
+}
+
+func (r *Router) Head(path string, handler HandlerFunc) *Route {
+	return r.AddHandler(HTTP_METHOD_HEAD, path, handler)
+}
+
+func (r *Router) Post(path string, handler HandlerFunc) *Route {
+	return r.AddHandler(HTTP_METHOD_POST, path, handler)
+}
+
+func (r *Router) Put(path string, handler HandlerFunc) *Route {
+	return r.AddHandler(HTTP_METHOD_PUT, path, handler)
+}
+
+func (r *Router) Delete(path string, handler HandlerFunc) *Route {
+	return r.AddHandler(HTTP_METHOD_DELETE, path, handler)
+}
+
+func (r *Router) Patch(path string, handler HandlerFunc) *Route {
+	return r.AddHandler(HTTP_METHOD_PATCH, path, handler)
+}
+
+// AddHandler registers the handler for method and path
+// and panics if the route can not be registered.
+// It is safe to call while serving requests
+func (r *Router) AddHandler(method, path string, handler HandlerFunc) *Route {
+	route, err := r.TryAddHandler(method, path, handler)
+	if err != nil {
+		panic(err)
+	}
+	return route
+}
+
+// TryAddHandler registers the handler for method and path.
+// It returns a *RouteError if the route is a duplicate,
+// conflicts with a registered one or is invalid.
+// It is safe to call while serving requests
+func (r *Router) TryAddHandler(method, path string, handler HandlerFunc) (*Route, error) {
+	routes, err := r.add([]string{method}, path, handler)
+	if err != nil {
+		return nil, err
+	}
+	return routes[0], nil
+}
+
+// Handle registers the handler for each of the methods, which can
+// be any method such as PROPFIND or MKCOL. It panics as AddHandler
+// and none of the routes is added then.
+// It is safe to call while serving requests
+func (r *Router) Handle(methods []string, path string, handler HandlerFunc) []*Route {
+	return r.mustAdd(methods, path, handler)
+}
+
+// Any registers the handler for all methods. It is matched with the
+// routes of the request method by specificity, so Any("/api/ping")
+// wins over Get("/*path") and Get("/api/:name") over Any("/api/*rest").
+// The route of the method wins if both are as specific. It also
+// takes OPTIONS and means there is no 405 for the path.
+// It panics as AddHandler.
+// It is safe to call while serving requests
+func (r *Router) Any(path string, handler HandlerFunc) *Route {
+	return r.AddHandler(HTTP_METHOD_ANY, path, handler)
+}
+
+// mustAdd registers the handler for the methods and panics
+// if the routes can not be registered
+func (r *Router) mustAdd(methods []string, path string, handler Handler) []*Route {
+	routes, err := r.add(methods, path, handler)
+	if err != nil {
+		panic(err)
+	}
+	return routes
+}
+
+// add registers the handler for the methods at once
+func (r *Router) add(methods []string, path string, handler Handler) ([]*Route, error) {
+	if len(methods) == 0 {
+		return nil, &RouteError{Pattern: path, Err: fmt.Errorf("%w: no method", ErrInvalidRoute)}
+	}
+	parsed, err := parseRoute(path)
+	if err != nil {
+		err.(*RouteError).Method = methods[0]
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u := r.newTreeUpdate()
+	routes := make([]*Route, 0, len(methods))
+	for _, method := range methods {
+		if method == "" {
+			return nil, &RouteError{Pattern: path, Err: fmt.Errorf("%w: empty method", ErrInvalidRoute)}
+		}
+		route := r.newRoute(method, parsed, handler)
+		if err := u.tree(method).insert(parsed, handler, r.StrictSlash); err != nil {
+			err.(*RouteError).Method = method
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	u.commit()
+	r.registry = append(r.registry, routes...)
+	return routes, nil
 }
 
-func (r *Router) Head(path string, handler HandlerFunc) {
-	r.AddHandler(HTTP_METHOD_HEAD, path, handler)
+// RemoveHandler removes the route for method and path
+// and reports whether there was one. A trailing slash
+// is not significant, /users/ removes /users, unless
+// StrictSlash is set.
+// It is safe to call while serving requests
+func (r *Router) RemoveHandler(method, path string) bool {
+	parsed, err := parseRoute(path)
+	if err != nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.indexOf(method, parsed)
+	if i == -1 {
+		return false
+	}
+	u := r.newTreeUpdate()
+	u.remove(r.registry[i])
+	u.commit()
+	registry := make([]*Route, 0, len(r.registry)-1)
+	registry = append(registry, r.registry[:i]...)
+	registry = append(registry, r.registry[i+1:]...)
+	r.registry = registry
+	return true
 }
 
-func (r *Router) Post(path string, handler HandlerFunc) {
-	r.AddHandler(HTTP_METHOD_POST, path, handler)
+// Replace sets the handler for method and path, the route
+// is added if there is none. Name and metadata are kept.
+// Requests being served keep the handler they matched.
+// It panics as AddHandler.
+// It is safe to call while serving requests
+func (r *Router) Replace(method, path string, handler HandlerFunc) *Route {
+	parsed, err := parseRoute(path)
+	if err != nil {
+		err.(*RouteError).Method = method
+		panic(err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	route := r.newRoute(method, parsed, handler)
+	u := r.newTreeUpdate()
+	i := r.indexOf(method, parsed)
+	if i != -1 {
+		route.name = r.registry[i].name
+		route.meta = r.registry[i].meta
+		route.namePrefix = r.registry[i].namePrefix
+		u.remove(r.registry[i])
+	}
+	if err := u.tree(method).insert(parsed, route.handler, r.StrictSlash); err != nil {
+		err.(*RouteError).Method = method
+		panic(err)
+	}
+	u.commit()
+	if i == -1 {
+		r.registry = append(r.registry, route)
+	} else {
+		registry := make([]*Route, len(r.registry))
+		copy(registry, r.registry)
+		registry[i] = route
+		r.registry = registry
+	}
+	return route
 }
 
-func (r *Router) Put(path string, handler HandlerFunc) {
-	r.AddHandler(HTTP_METHOD_PUT, path, handler)
+// trees returns the current method trees
+func (r *Router) trees() map[string]*rootNode {
+	roots, _ := r.roots.Load().(map[string]*rootNode)
+	return roots
 }
 
-func (r *Router) Delete(path string, handler HandlerFunc) {
-	r.AddHandler(HTTP_METHOD_DELETE, path, handler)
+// indexOf returns the index of the route in the registry, -1 if none.
+// The one with the same trailing slash is taken first, in strict
+// slash mode the trailing slash must be the same
+func (r *Router) indexOf(method string, parsed *routePattern) int {
+	found := -1
+	for i, entry := range r.registry {
+		if entry.method != method || entry.parsed.key != parsed.key {
+			continue
+		}
+		if entry.parsed.leafIndex() == parsed.leafIndex() {
+			return i
+		}
+		if found == -1 && !r.StrictSlash {
+			found = i
+		}
+	}
+	return found
+}
+
+// treeUpdate is a change of the method trees, made on copies
+// of the changed nodes and swapped in at once by commit.
+// Until then requests are served by the current trees
+type treeUpdate struct {
+	router *Router
+	gen    uint64
+	roots  map[string]*rootNode
+}
+
+// newTreeUpdate starts a change of the trees, the caller must
+// hold mu until it is committed or dropped
+func (r *Router) newTreeUpdate() *treeUpdate {
+	r.gen++
+	current := r.trees()
+	roots := make(map[string]*rootNode, len(current)+1)
+	for m, tree := range current {
+		roots[m] = tree
+	}
+	return &treeUpdate{router: r, gen: r.gen, roots: roots}
 }
 
-func (r *Router) Patch(path string, handler HandlerFunc) {
-	r.AddHandler(HTTP_METHOD_PATCH, path, handler)
+// tree returns the tree of the method to change
+func (u *treeUpdate) tree(method string) *rootNode {
+	tree := u.roots[method]
+	if tree == nil {
+		tree = newRouteTree()
+		tree.gen = u.gen
+		tree.root.gen = u.gen
+	} else if tree.gen != u.gen {
+		tree = tree.clone(u.gen)
+	}
+	u.roots[method] = tree
+	return tree
 }
 
-func (r *Router) AddHandler(method, path string, handler HandlerFunc) {
-	if _, exists := r.roots[method]; !exists {
-		r.roots[method] = newRouteTree()
+// remove removes the registered route from its tree,
+// the tree is dropped if it has no routes left
+func (u *treeUpdate) remove(route *Route) {
+	tree := u.tree(route.method)
+	tree.remove(route.parsed)
+	if tree.routes == 0 {
+		delete(u.roots, route.method)
 	}
-	r.roots[method].addRoute(path, HandlerFunc(handler))
+}
+
+// commit swaps in the changed trees
+func (u *treeUpdate) commit() {
+	u.router.roots.Store(u.roots)
 }
 
 // Group takes a path which typically a prefix for an endpoint
@@ -140,11 +637,13 @@ func (r *Router) Group(path string, fn func(r *GroupRouter)) {
 }
 
 
+// Dump returns the route trees as text, methods in sorted order
 func (r *Router) Dump() string {
 	s := ""
-	for method, root := range r.roots {
-		s += method + "\n" +root.dump()
+	roots := r.trees()
+	for _, method := range sortedMethods(roots) {
+		s += method + "\n" + roots[method].dump()
 	}
-	
+
 	return s
-}
\ No newline at end of file
+}
@@ -1,11 +1,14 @@
 package r2router
 
 import (
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
-	//"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -201,6 +204,21 @@ func TestRouterFirstNodeParamNode(t *testing.T) {
 	assert.Equal(t, string(content), "GET:/user/keys/:id,testing")
 }
 
+func TestRouterParamNames(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:user-id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("user:" + p.Get("user-id")))
+	})
+	router.Get("/posts/:id-:slug", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("post:" + p.Get("id") + ":" + p.Get("slug")))
+	})
+	w := serveHost(router, "GET", "example.com", "/users/42")
+	assert.Equal(t, w.Body.String(), "user:42")
+	w = serveHost(router, "GET", "example.com", "/posts/7-hello")
+	assert.Equal(t, w.Body.String(), "post:7:hello")
+	assert.Equal(t, router.Routes()[0].Params, []string{"user-id"})
+}
+
 func TestRouterDump(t *testing.T) {
 	router := NewRouter()
 	router.Get("/:page", func(w http.ResponseWriter, r *http.Request, p Params) {
@@ -208,3 +226,781 @@ func TestRouterDump(t *testing.T) {
 	})
 	assert.Contains(t, router.Dump(), " |\n  -- \n  |\n   -- :page (<")
 }
+
+func TestRouterDumpWildcard(t *testing.T) {
+	router := NewRouter()
+	router.Get("/static/*filepath", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(p.Get("filepath")))
+	})
+	assert.Contains(t, router.Dump(), "-- static\n   |\n    -- *filepath (<")
+}
+
+func TestRouterConstrainedParams(t *testing.T) {
+	router := NewRouter()
+	router.Get(`/users/:id(\d+)`, func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("GET:/users/:id," + p.Get("id")))
+	})
+	router.Get("/users/:name<alpha>", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("GET:/users/:name," + p.Get("name")))
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/users/42")
+	assert.Nil(t, err)
+	content, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, string(content), "GET:/users/:id,42")
+
+	res, err = http.Get(ts.URL + "/users/vanng")
+	assert.Nil(t, err)
+	content, err = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, string(content), "GET:/users/:name,vanng")
+
+	res, err = http.Get(ts.URL + "/users/vanng822")
+	assert.Nil(t, err)
+	res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusNotFound)
+
+	assert.Contains(t, router.Dump(), `-- :id(\d+) (<`)
+	assert.Contains(t, router.Dump(), "-- :name<alpha> (<")
+}
+
+func TestRouterDumpOptional(t *testing.T) {
+	router := NewRouter()
+	router.Get("/docs/:lang?=en/:page", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(p.Get("lang") + ":" + p.Get("page")))
+	})
+	router.Get("/reports/:year/:month?", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(p.Get("year") + ":" + p.Get("month")))
+	})
+	dump := router.Dump()
+	assert.Contains(t, dump, "-- :lang?=en\n")
+	assert.Contains(t, dump, "-- :month? (<")
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/docs/intro")
+	assert.Nil(t, err)
+	content, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, string(content), "en:intro")
+
+	res, err = http.Get(ts.URL + "/reports/2015")
+	assert.Nil(t, err)
+	content, err = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, string(content), "2015:")
+}
+
+func TestRouterStrictSlash(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("GET:/users"))
+	})
+	router.Post("/users/:id/keys/", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("POST:/users/:id/keys/," + p.Get("id")))
+	})
+	router.Get("/static/*filepath", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("GET:/static/*filepath," + p.Get("filepath")))
+	})
+
+	// default is not strict
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/", nil))
+	assert.Equal(t, w.Body.String(), "GET:/users")
+
+	router.StrictSlash = true
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/", nil))
+	assert.Equal(t, w.Code, http.StatusNotFound)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/users/1/keys", nil))
+	assert.Equal(t, w.Code, http.StatusNotFound)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/users/1/keys/", nil))
+	assert.Equal(t, w.Body.String(), "POST:/users/:id/keys/,1")
+
+	// catch-all takes any
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/static/css/", nil))
+	assert.Equal(t, w.Body.String(), "GET:/static/*filepath,css/")
+
+	router.RedirectTrailingSlash = true
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/?page=2", nil))
+	assert.Equal(t, w.Code, http.StatusMovedPermanently)
+	assert.Equal(t, w.Header().Get("Location"), "/users?page=2")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/users/1/keys", nil))
+	assert.Equal(t, w.Code, http.StatusPermanentRedirect)
+	assert.Equal(t, w.Header().Get("Location"), "/users/1/keys/")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/1/keys/", nil))
+	assert.Equal(t, w.Code, http.StatusMethodNotAllowed)
+}
+
+func TestRouterStrictSlashBacktrack(t *testing.T) {
+	router := NewRouter()
+	router.StrictSlash = true
+	router.Get("/a/:x", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("/a/:x," + p.Get("x")))
+	})
+	router.Get("/a/b/", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("/a/b/"))
+	})
+	w := serveHost(router, "GET", "example.com", "/a/b")
+	assert.Equal(t, w.Body.String(), "/a/:x,b")
+	w = serveHost(router, "GET", "example.com", "/a/b/")
+	assert.Equal(t, w.Body.String(), "/a/b/")
+	w = serveHost(router, "GET", "example.com", "/a/c/")
+	assert.Equal(t, w.Code, http.StatusNotFound)
+}
+
+func TestRouterStrictSlashBothRoutes(t *testing.T) {
+	router := NewRouter()
+	router.StrictSlash = true
+	router.Get("/users", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("/users"))
+	})
+	router.Get("/users/", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("/users/"))
+	})
+	w := serveHost(router, "GET", "example.com", "/users")
+	assert.Equal(t, w.Body.String(), "/users")
+	w = serveHost(router, "GET", "example.com", "/users/")
+	assert.Equal(t, w.Body.String(), "/users/")
+	tree := router.Tree()["GET"].Children[0]
+	assert.Equal(t, tree.Pattern, "/users")
+	assert.Equal(t, tree.Children[0].Path, "/")
+	assert.Equal(t, tree.Children[0].Pattern, "/users/")
+
+	// only the one with the same trailing slash
+	assert.True(t, router.RemoveHandler("GET", "/users/"))
+	w = serveHost(router, "GET", "example.com", "/users")
+	assert.Equal(t, w.Body.String(), "/users")
+	w = serveHost(router, "GET", "example.com", "/users/")
+	assert.Equal(t, w.Code, http.StatusNotFound)
+
+	// same route if not strict
+	router.StrictSlash = false
+	_, err := router.TryAddHandler("GET", "/users/", func(w http.ResponseWriter, r *http.Request, p Params) {})
+	assert.True(t, errors.Is(err, ErrDuplicateRoute))
+}
+
+func TestRouterRedirectSameHost(t *testing.T) {
+	router := NewRouter()
+	router.StrictSlash = true
+	router.RedirectTrailingSlash = true
+	router.Get("/:page/", func(w http.ResponseWriter, r *http.Request, p Params) {})
+
+	for path, location := range map[string]string{
+		"//evil.com":  "/evil.com/",
+		"///evil.com": "/evil.com/",
+		// a backslash is escaped in the path
+		"/\\evil.com": "/%5Cevil.com/",
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.URL.Path = path
+		router.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, http.StatusMovedPermanently, path)
+		assert.Equal(t, w.Header().Get("Location"), location, path)
+	}
+}
+
+func TestRouterCleanPath(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id/keys", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("GET:/users/:id/keys," + p.Get("id")))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/1/../2/./keys", nil))
+	assert.Equal(t, w.Code, http.StatusNotFound)
+
+	router.CleanPath = true
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/1/../2/./keys", nil))
+	assert.Equal(t, w.Body.String(), "GET:/users/:id/keys,2")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost//users//3/keys", nil))
+	assert.Equal(t, w.Body.String(), "GET:/users/:id/keys,3")
+
+	router.RedirectCleanPath = true
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/1/../2/./keys?all=1", nil))
+	assert.Equal(t, w.Code, http.StatusMovedPermanently)
+	assert.Equal(t, w.Header().Get("Location"), "/users/2/keys?all=1")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("PUT", "/users/2/./keys", nil))
+	assert.Equal(t, w.Code, http.StatusPermanentRedirect)
+	assert.Equal(t, w.Header().Get("Location"), "/users/2/keys")
+}
+
+func TestRouterCleanPathEscaped(t *testing.T) {
+	router := NewRouter()
+	router.CleanPath = true
+	router.Get("/files/*filepath", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(p.Get("filepath")))
+	})
+
+	// escaped slashes are not segments to clean
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/files//a%2F..%2F..%2Fx", nil))
+	assert.Equal(t, w.Body.String(), "a/../../x")
+
+	router.RedirectCleanPath = true
+	for path, location := range map[string]string{
+		"/files//a%3Fb?x=1":       "/files/a%3Fb?x=1",
+		"/files//a%20b":           "/files/a%20b",
+		"/files//a%2F..%2F..%2Fx": "/files/a%2F..%2F..%2Fx",
+		"/files/a/%2E%2E/../b":    "/files/a/b",
+	} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, w.Code, http.StatusMovedPermanently, path)
+		assert.Equal(t, w.Header().Get("Location"), location, path)
+	}
+
+	// the mount prefix is kept escaped
+	parent := NewRouter()
+	parent.Mount("/my files", router)
+	w = httptest.NewRecorder()
+	parent.ServeHTTP(w, httptest.NewRequest("GET", "/my%20files/files//a%3Fb", nil))
+	assert.Equal(t, w.Header().Get("Location"), "/my%20files/files/a%3Fb")
+}
+
+func TestRouterCaseInsensitive(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("GET:/users/:id," + p.Get("id")))
+	})
+	router.Put("/Users/:id/Keys/", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("PUT:/Users/:id/Keys/," + p.Get("id")))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/USERS/VanNg", nil))
+	assert.Equal(t, w.Code, http.StatusNotFound)
+
+	router.CaseInsensitive = true
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/USERS/VanNg", nil))
+	assert.Equal(t, w.Body.String(), "GET:/users/:id,VanNg")
+
+	router.CaseInsensitive = false
+	router.RedirectFixedCase = true
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/USERS/VanNg?x=1", nil))
+	assert.Equal(t, w.Code, http.StatusMovedPermanently)
+	assert.Equal(t, w.Header().Get("Location"), "/users/VanNg?x=1")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("PUT", "/users/42/keys/", nil))
+	assert.Equal(t, w.Code, http.StatusPermanentRedirect)
+	assert.Equal(t, w.Header().Get("Location"), "/Users/42/Keys/")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/members/42", nil))
+	assert.Equal(t, w.Code, http.StatusNotFound)
+}
+
+func TestRouterFixedCaseEscaped(t *testing.T) {
+	router := NewRouter()
+	router.RedirectFixedCase = true
+	router.Get("/Docs/:name/", func(w http.ResponseWriter, r *http.Request, p Params) {})
+
+	for _, useEscapedPath := range []bool{false, true} {
+		router.UseEscapedPath = useEscapedPath
+		for path, location := range map[string]string{
+			"/docs/a%3Fb/":   "/Docs/a%3Fb/",
+			"/docs/a%20b/":   "/Docs/a%20b/",
+			"/docs/a%2541b/": "/Docs/a%2541b/",
+		} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+			assert.Equal(t, w.Code, http.StatusMovedPermanently, path)
+			assert.Equal(t, w.Header().Get("Location"), location, path)
+		}
+	}
+}
+
+func TestRouterEscapedPath(t *testing.T) {
+	router := NewRouter()
+	router.Get("/files/:id/:name", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("GET:/files/:id/:name," + p.Get("id") + "," + p.Get("name")))
+	})
+	router.Get("/static/*filepath", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("GET:/static/*filepath," + p.Get("filepath")))
+	})
+
+	// decoded path splits on the encoded slash
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/files/a%2Fb/my%20report", nil))
+	assert.Equal(t, w.Code, http.StatusNotFound)
+
+	router.UseEscapedPath = true
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/files/a%2Fb/my%20report", nil))
+	assert.Equal(t, w.Body.String(), "GET:/files/:id/:name,a/b,my report")
+
+	// an encoded percent stays a percent
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/files/100%25/a%2520b", nil))
+	assert.Equal(t, w.Body.String(), "GET:/files/:id/:name,100%,a%20b")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/static/my%20docs/a%3Fb.txt", nil))
+	assert.Equal(t, w.Body.String(), "GET:/static/*filepath,my docs/a?b.txt")
+
+	// round trip with the route manager
+	m := NewRouteManager()
+	url := m.UrlForPath("/files/:id/:name", P{"id": []string{"x/y z"}, "name": []string{"50%"}})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+	assert.Equal(t, w.Body.String(), "GET:/files/:id/:name,x/y z,50%")
+}
+
+func TestRouterRemoveHandler(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("user:" + p.Get("id")))
+	})
+	router.Get("/users/:id/keys", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("keys:" + p.Get("id")))
+	})
+	router.Post("/users", func(w http.ResponseWriter, r *http.Request, p Params) {})
+
+	assert.True(t, router.RemoveHandler("GET", "/users/:id/"))
+	assert.False(t, router.RemoveHandler("GET", "/users/:id"))
+	assert.False(t, router.RemoveHandler("PUT", "/users/:id/keys"))
+
+	w := serveHost(router, "GET", "example.com", "/users/1")
+	assert.Equal(t, w.Code, http.StatusNotFound)
+	w = serveHost(router, "GET", "example.com", "/users/1/keys")
+	assert.Equal(t, w.Body.String(), "keys:1")
+
+	// no method tree left for POST, so no 405 for it
+	assert.True(t, router.RemoveHandler("POST", "/users"))
+	_, exists := router.trees()["POST"]
+	assert.False(t, exists)
+	w = serveHost(router, "PUT", "example.com", "/users")
+	assert.Equal(t, w.Code, http.StatusNotFound)
+}
+
+func TestRouterReplace(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("v1:" + p.Get("id")))
+	})
+	router.Replace("GET", "/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("v2:" + p.Get("id")))
+	})
+	w := serveHost(router, "GET", "example.com", "/users/1")
+	assert.Equal(t, w.Body.String(), "v2:1")
+
+	// added if there is none
+	router.Replace("GET", "/keys", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("keys"))
+	})
+	w = serveHost(router, "GET", "example.com", "/keys")
+	assert.Equal(t, w.Body.String(), "keys")
+	assert.Equal(t, len(router.registry), 2)
+}
+
+func TestRouterAddHandlerPanicKeepsRoutes(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("user:" + p.Get("id")))
+	})
+	roots := router.trees()
+	assert.Panics(t, func() {
+		router.Get("/users/:uid/keys", func(w http.ResponseWriter, r *http.Request, p Params) {})
+	})
+	assert.Equal(t, len(router.registry), 1)
+	assert.True(t, router.trees()["GET"] == roots["GET"])
+	w := serveHost(router, "GET", "example.com", "/users/1")
+	assert.Equal(t, w.Body.String(), "user:1")
+
+	// the lock is released
+	router.Get("/keys", func(w http.ResponseWriter, r *http.Request, p Params) {})
+	assert.Equal(t, len(router.registry), 2)
+}
+
+func TestRouterConcurrentChanges(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("user:" + p.Get("id")))
+	})
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			path := fmt.Sprintf("/tenants/t%d/:id", i)
+			router.Get(path, func(w http.ResponseWriter, r *http.Request, p Params) {})
+			if i%2 == 0 {
+				router.RemoveHandler("GET", path)
+			}
+		}
+		done <- true
+	}()
+	for i := 0; i < 200; i++ {
+		w := serveHost(router, "GET", "example.com", "/users/1")
+		assert.Equal(t, w.Body.String(), "user:1")
+	}
+	<-done
+	assert.Equal(t, len(router.registry), 51)
+}
+
+func TestRouterTryAddHandler(t *testing.T) {
+	router := NewRouter()
+	handler := func(w http.ResponseWriter, r *http.Request, p Params) {}
+	route, err := router.TryAddHandler("GET", "/users/:id", handler)
+	assert.Nil(t, err)
+	assert.NotNil(t, route)
+
+	route, err = router.TryAddHandler("GET", "/users/:id", handler)
+	assert.Nil(t, route)
+	assert.True(t, errors.Is(err, ErrDuplicateRoute))
+	assert.Equal(t, err.Error(), "GET /users/:id: duplicate route with /users/:id")
+
+	_, err = router.TryAddHandler("GET", "/users/:uid/keys", handler)
+	assert.True(t, errors.Is(err, ErrParamConflict))
+	assert.Equal(t, err.(*RouteError).Existing, "/users/:id")
+
+	_, err = router.TryAddHandler("POST", "/users/:", handler)
+	assert.True(t, errors.Is(err, ErrInvalidRoute))
+	_, exists := router.trees()["POST"]
+	assert.False(t, exists)
+
+	_, err = router.TryAddHandler("POST", "/users/:uid", handler)
+	assert.Nil(t, err)
+	assert.Equal(t, len(router.registry), 2)
+
+	assert.Panics(t, func() {
+		router.Replace("GET", "/users/:id/keys/:kid", handler)
+		router.Replace("GET", "/users/:id/keys/:other", handler)
+	})
+}
+
+func TestRouterHandle(t *testing.T) {
+	router := NewRouter()
+	routes := router.Handle([]string{"PROPFIND", "MKCOL", "GET"}, "/dav/*path", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(r.Method + ":" + p.Get("path")))
+	})
+	assert.Equal(t, len(routes), 3)
+	router.Handle([]string{"CONNECT", "TRACE"}, "/debug", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(r.Method))
+	})
+
+	w := serveHost(router, "PROPFIND", "example.com", "/dav/a/b")
+	assert.Equal(t, w.Body.String(), "PROPFIND:a/b")
+	w = serveHost(router, "MKCOL", "example.com", "/dav/c")
+	assert.Equal(t, w.Body.String(), "MKCOL:c")
+	w = serveHost(router, "TRACE", "example.com", "/debug")
+	assert.Equal(t, w.Body.String(), "TRACE")
+
+	w = serveHost(router, "PUT", "example.com", "/dav/a")
+	assert.Equal(t, w.Code, http.StatusMethodNotAllowed)
+
+	w = serveHost(router, "OPTIONS", "example.com", "/dav/a")
+	assert.Equal(t, w.Code, http.StatusOK)
+	allow := strings.Split(w.Header().Get("Allow"), ", ")
+	sort.Strings(allow)
+	assert.Equal(t, allow, []string{"GET", "MKCOL", "PROPFIND"})
+}
+
+func TestRouterHandleAtomic(t *testing.T) {
+	router := NewRouter()
+	handler := func(w http.ResponseWriter, r *http.Request, p Params) {}
+	router.Post("/users", handler)
+
+	assert.Panics(t, func() {
+		router.Handle([]string{"GET", "POST"}, "/users", handler)
+	})
+	_, exists := router.trees()["GET"]
+	assert.False(t, exists)
+	assert.Equal(t, len(router.Routes()), 1)
+
+	assert.Panics(t, func() {
+		router.Handle([]string{}, "/users", handler)
+	})
+	assert.Panics(t, func() {
+		router.Handle([]string{"GET", ""}, "/users", handler)
+	})
+	assert.Panics(t, func() {
+		router.Handle([]string{"GET", "GET"}, "/users", handler)
+	})
+	assert.Equal(t, len(router.Routes()), 1)
+}
+
+func TestRouterAny(t *testing.T) {
+	router := NewRouter()
+	router.Any("/proxy/*rest", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("any:" + r.Method + ":" + p.Get("rest")))
+	})
+	router.Get("/proxy/status", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("status"))
+	})
+	router.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {})
+
+	w := serveHost(router, "GET", "example.com", "/proxy/status")
+	assert.Equal(t, w.Body.String(), "status")
+	w = serveHost(router, "POST", "example.com", "/proxy/status")
+	assert.Equal(t, w.Body.String(), "any:POST:status")
+	w = serveHost(router, "PROPFIND", "example.com", "/proxy/a/b")
+	assert.Equal(t, w.Body.String(), "any:PROPFIND:a/b")
+	w = serveHost(router, "OPTIONS", "example.com", "/proxy/a")
+	assert.Equal(t, w.Body.String(), "any:OPTIONS:a")
+
+	// Any is not listed
+	w = serveHost(router, "OPTIONS", "example.com", "/users/1")
+	assert.Equal(t, w.Header().Get("Allow"), "GET")
+	w = serveHost(router, "DELETE", "example.com", "/users/1")
+	assert.Equal(t, w.Code, http.StatusMethodNotAllowed)
+
+	assert.True(t, router.RemoveHandler(HTTP_METHOD_ANY, "/proxy/*rest"))
+	w = serveHost(router, "POST", "example.com", "/proxy/status")
+	assert.Equal(t, w.Code, http.StatusMethodNotAllowed)
+}
+
+func TestRouterAnySpecificity(t *testing.T) {
+	router := NewRouter()
+	router.Get("/*any", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("get:" + p.Get("any")))
+	})
+	router.Any("/api/ping", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("ping"))
+	})
+	router.Get("/api/:name", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("name:" + p.Get("name")))
+	})
+	router.Any("/api/:version/*rest", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("any:" + p.Get("version") + ":" + p.Get("rest")))
+	})
+	router.Any("/api/:version", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("version:" + p.Get("version")))
+	})
+
+	w := serveHost(router, "GET", "example.com", "/api/ping")
+	assert.Equal(t, w.Body.String(), "ping")
+	w = serveHost(router, "POST", "example.com", "/api/ping")
+	assert.Equal(t, w.Body.String(), "ping")
+	// the method wins if both are as specific
+	w = serveHost(router, "GET", "example.com", "/api/users")
+	assert.Equal(t, w.Body.String(), "name:users")
+	w = serveHost(router, "POST", "example.com", "/api/users")
+	assert.Equal(t, w.Body.String(), "version:users")
+	// only params of the route taken
+	w = serveHost(router, "GET", "example.com", "/api/v1/users/1")
+	assert.Equal(t, w.Body.String(), "any:v1:users/1")
+	w = serveHost(router, "GET", "example.com", "/docs/index.html")
+	assert.Equal(t, w.Body.String(), "get:docs/index.html")
+
+	router.RedirectFixedCase = true
+	router.Any("/Docs/*rest", func(w http.ResponseWriter, r *http.Request, p Params) {})
+	w = serveHost(router, "POST", "example.com", "/API/Ping")
+	assert.Equal(t, w.Header().Get("Location"), "/api/ping")
+	w = serveHost(router, "GET", "example.com", "/docs/a")
+	assert.Equal(t, w.Body.String(), "get:docs/a")
+	w = serveHost(router, "POST", "example.com", "/docs/a")
+	assert.Equal(t, w.Header().Get("Location"), "/Docs/a")
+	router.Post("/Users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {})
+	router.Any("/users/*rest", func(w http.ResponseWriter, r *http.Request, p Params) {})
+	w = serveHost(router, "POST", "example.com", "/USERS/1")
+	assert.Equal(t, w.Header().Get("Location"), "/Users/1")
+}
+
+func TestRouterAnyCaseInsensitive(t *testing.T) {
+	router := NewRouter()
+	router.CaseInsensitive = true
+	router.Any("/webhooks/:name", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("hook:" + p.Get("name")))
+	})
+	w := serveHost(router, "POST", "example.com", "/WebHooks/Github")
+	assert.Equal(t, w.Body.String(), "hook:Github")
+
+	router.CaseInsensitive = false
+	router.RedirectFixedCase = true
+	w = serveHost(router, "POST", "example.com", "/WebHooks/Github")
+	assert.Equal(t, w.Code, http.StatusPermanentRedirect)
+	assert.Equal(t, w.Header().Get("Location"), "/webhooks/Github")
+}
+
+func TestRouterHandleHEAD(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Header().Set("X-User", p.Get("id"))
+		w.Write([]byte("user:" + p.Get("id")))
+	})
+	router.Get("/keys", func(w http.ResponseWriter, r *http.Request, p Params) {
+		// the writer is not wrapped
+		if _, ok := w.(http.Flusher); ok {
+			w.Header().Set("X-Flusher", "1")
+		}
+		w.Write([]byte("keys"))
+	})
+	router.Head("/keys", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Header().Set("X-Head", "keys")
+	})
+
+	// off by default
+	w := serveHost(router, "HEAD", "example.com", "/users/1")
+	assert.Equal(t, w.Code, http.StatusMethodNotAllowed)
+
+	router.HandleHEAD = true
+	w = serveHost(router, "HEAD", "example.com", "/users/1")
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Header().Get("X-User"), "1")
+
+	// net/http discards the body
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+	res, err := http.Head(ts.URL + "/users/1")
+	assert.Nil(t, err)
+	content, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.Equal(t, len(content), 0)
+
+	w = serveHost(router, "HEAD", "example.com", "/keys")
+	assert.Equal(t, w.Header().Get("X-Head"), "keys")
+	router.RemoveHandler("HEAD", "/keys")
+	w = serveHost(router, "HEAD", "example.com", "/keys")
+	assert.Equal(t, w.Header().Get("X-Flusher"), "1")
+
+	w = serveHost(router, "GET", "example.com", "/users/1")
+	assert.Equal(t, w.Body.String(), "user:1")
+}
+
+func TestRouterAllowHeader(t *testing.T) {
+	router := NewRouter()
+	handler := func(w http.ResponseWriter, r *http.Request, p Params) {}
+	for _, method := range []string{"PUT", "GET", "DELETE", "PATCH", "POST"} {
+		router.AddHandler(method, "/users/:id", handler)
+	}
+	router.Any("/proxy/*rest", handler)
+
+	w := serveHost(router, "OPTIONS", "example.com", "/users/1")
+	assert.Equal(t, w.Header().Get("Allow"), "DELETE, GET, PATCH, POST, PUT")
+
+	w = serveHost(router, "PROPFIND", "example.com", "/users/1")
+	assert.Equal(t, w.Code, http.StatusMethodNotAllowed)
+	assert.Equal(t, w.Header().Get("Allow"), "")
+
+	router.EnableMethodCompliance()
+	w = serveHost(router, "PROPFIND", "example.com", "/users/1")
+	assert.Equal(t, w.Code, http.StatusMethodNotAllowed)
+	assert.Equal(t, w.Header().Get("Allow"), "DELETE, GET, HEAD, PATCH, POST, PUT")
+
+	w = serveHost(router, "OPTIONS", "example.com", "/users/1")
+	assert.Equal(t, w.Header().Get("Allow"), "DELETE, GET, HEAD, PATCH, POST, PUT")
+
+	router.MethodNotAllowed = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}
+	w = serveHost(router, "PROPFIND", "example.com", "/users/1")
+	assert.Equal(t, w.Code, http.StatusTeapot)
+	assert.Equal(t, w.Header().Get("Allow"), "DELETE, GET, HEAD, PATCH, POST, PUT")
+}
+
+func TestRouterOptionsAsterisk(t *testing.T) {
+	router := NewRouter()
+	handler := func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("page"))
+	}
+	router.Get("/:page", handler)
+	router.Post("/users", handler)
+	router.Handle([]string{"MKCOL"}, "/dav/*path", handler)
+	router.CleanPath = true
+	router.RedirectCleanPath = true
+
+	req := httptest.NewRequest("OPTIONS", "*", nil)
+	assert.Equal(t, req.URL.Path, "*")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, w.Code, http.StatusPermanentRedirect)
+
+	router.HandleOptionsAsterisk = true
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Header().Get("Allow"), "GET, MKCOL, POST")
+
+	router.HandleHEAD = true
+	router.Options = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Methods", w.Header().Get("Allow"))
+		w.WriteHeader(http.StatusNoContent)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, w.Code, http.StatusNoContent)
+	assert.Equal(t, w.Header().Get("Access-Control-Allow-Methods"), "GET, HEAD, MKCOL, POST")
+
+	w = serveHost(router, "OPTIONS", "example.com", "/users")
+	assert.Equal(t, w.Code, http.StatusNoContent)
+	assert.Equal(t, w.Header().Get("Allow"), "GET, HEAD, POST")
+}
+
+func TestRouterPathValue(t *testing.T) {
+	router := NewRouter()
+	router.SetPathValues = true
+	router.Get("/users/:id/files/*filepath", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(r.PathValue("id") + ":" + r.PathValue("filepath")))
+	})
+	api := NewSeeforRouter()
+	api.SetPathValues = true
+	api.Get("/keys/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(r.PathValue("id") + ":" + r.PathValue("user")))
+	})
+	router.Mount("/users/:user", api)
+
+	assert.Equal(t, serveHost(router, "GET", "", "/users/1/files/a/b.txt").Body.String(), "1:a/b.txt")
+	// own params win over those of the parent router
+	assert.Equal(t, serveHost(router, "GET", "", "/users/2/keys/3").Body.String(), "3:2")
+
+	router.SetPathValues = false
+	assert.Equal(t, serveHost(router, "GET", "", "/users/1/files/a/b.txt").Body.String(), ":")
+}
+
+func TestRouterBracePatterns(t *testing.T) {
+	router := NewRouter()
+	router.SetPathValues = true
+	router.Get("/users/{id}/files/{filepath...}", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(p.Get("id") + ":" + r.PathValue("filepath")))
+	})
+	router.Get(`/orders/{id}/items/:item(\d{2})`, func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(p.Get("id") + ":" + p.Get("item")))
+	})
+
+	assert.Equal(t, serveHost(router, "GET", "", "/users/1/files/a/b.txt").Body.String(), "1:a/b.txt")
+	assert.Equal(t, serveHost(router, "GET", "", "/orders/1/items/12").Body.String(), "1:12")
+	assert.Equal(t, serveHost(router, "GET", "", "/orders/1/items/123").Code, http.StatusNotFound)
+
+	routes := router.Routes()
+	assert.Equal(t, routes[0].Pattern, "/users/{id}/files/{filepath...}")
+	assert.Equal(t, routes[0].Params, []string{"id", "filepath"})
+
+	// the same route in either form
+	_, err := router.TryAddHandler("GET", "/users/:id/files/*filepath", func(w http.ResponseWriter, r *http.Request, p Params) {})
+	assert.True(t, errors.Is(err, ErrDuplicateRoute))
+	assert.True(t, router.RemoveHandler("GET", "/users/:id/files/*filepath"))
+	assert.Equal(t, serveHost(router, "GET", "", "/users/1/files/a/b.txt").Code, http.StatusNotFound)
+
+	_, err = router.TryAddHandler("GET", "/bad/{...}", func(w http.ResponseWriter, r *http.Request, p Params) {})
+	assert.True(t, errors.Is(err, ErrInvalidRoute))
+	_, err = router.TryAddHandler("GET", "/bad/{rest...}/more", func(w http.ResponseWriter, r *http.Request, p Params) {})
+	assert.True(t, errors.Is(err, ErrInvalidRoute))
+}
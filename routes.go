@@ -0,0 +1,128 @@
+package r2router
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Route is a registered route. It is returned when adding
+// a handler and can be given a name and metadata
+// router.Get("/users/:id", user).Name("user").Meta("auth", true)
+type Route struct {
+	router  *Router
+	method  string
+	path    string
+	handler Handler
+	name    string
+	meta    map[string]interface{}
+	// namePrefix is the name prefix of the group
+	namePrefix string
+	// parsed is the parsed path, shared by routes added at once
+	parsed *routePattern
+}
+
+// RouteInfo describes a registered route, see Router.Routes
+type RouteInfo struct {
+	Method  string
+	Pattern string
+	// Params holds the param names in path order
+	Params []string
+	Name   string
+	// Handler is the function name of the handler,
+	// or its type if it is not a function
+	Handler string
+	Meta    map[string]interface{}
+}
+
+func (r *Router) newRoute(method string, parsed *routePattern, handler Handler) *Route {
+	return &Route{router: r, method: method, path: parsed.pattern, handler: handler, parsed: parsed}
+}
+
+// Name sets the name of the route, after
+// the name prefix of its group if any
+func (rt *Route) Name(name string) *Route {
+	rt.router.mu.Lock()
+	defer rt.router.mu.Unlock()
+	rt.name = rt.namePrefix + name
+	return rt
+}
+
+// Meta sets metadata for the route, such as required
+// permissions, for tools reading the route table
+func (rt *Route) Meta(key string, value interface{}) *Route {
+	rt.router.mu.Lock()
+	defer rt.router.mu.Unlock()
+	meta := make(map[string]interface{}, len(rt.meta)+1)
+	for k, v := range rt.meta {
+		meta[k] = v
+	}
+	meta[key] = value
+	// RouteInfo shares the map, so it is never changed
+	rt.meta = meta
+	return rt
+}
+
+// Routes returns the registered routes in the order
+// they were added. Changes after the call are not reflected
+func (r *Router) Routes() []RouteInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	routes := make([]RouteInfo, 0, len(r.registry))
+	for _, route := range r.registry {
+		routes = append(routes, RouteInfo{
+			Method:  route.method,
+			Pattern: route.path,
+			Params:  routeParams(route.path),
+			Name:    route.name,
+			Handler: handlerName(route.handler),
+			Meta:    route.meta,
+		})
+	}
+	return routes
+}
+
+// routeParams returns the param names of a valid route pattern
+func routeParams(path string) []string {
+	names := make([]string, 0)
+	for _, token := range splitPattern(strings.Trim(path, "/")) {
+		if strings.HasPrefix(token, "*") {
+			names = append(names, strings.TrimSpace(token[1:]))
+			continue
+		}
+		parts, _ := parseSegment(token)
+		for _, part := range parts {
+			if part.param {
+				names = append(names, part.text)
+			}
+		}
+	}
+	return names
+}
+
+// handlerName returns the function name of the handler
+// or its type if it is not a function
+func handlerName(handler interface{}) string {
+	switch h := handler.(type) {
+	case HandlerFunc:
+		return funcName(h)
+	case http.HandlerFunc:
+		return funcName(h)
+	case *mountHandler:
+		return handlerName(h.handler)
+	case *chainHandler:
+		return handlerName(h.handler)
+	case *httpHandler:
+		return handlerName(h.handler)
+	}
+	return fmt.Sprintf("%T", handler)
+}
+
+func funcName(fn interface{}) string {
+	if f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()); f != nil {
+		return f.Name()
+	}
+	return fmt.Sprintf("%T", fn)
+}
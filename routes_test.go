@@ -0,0 +1,80 @@
+package r2router
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func routesTestHandler(w http.ResponseWriter, req *http.Request, p Params) {}
+
+func TestRouterRoutes(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id", routesTestHandler).Name("user").Meta("auth", true)
+	router.Post(`/users/:id(\d+)/files/*filepath`, routesTestHandler)
+	router.Group("/api", func(r *GroupRouter) {
+		r.Get("/:version/:name.:ext", routesTestHandler).Name("api")
+	})
+	router.Delete("/", func(w http.ResponseWriter, req *http.Request, p Params) {})
+
+	routes := router.Routes()
+	assert.Equal(t, len(routes), 4)
+
+	assert.Equal(t, routes[0].Method, "GET")
+	assert.Equal(t, routes[0].Pattern, "/users/:id")
+	assert.Equal(t, routes[0].Params, []string{"id"})
+	assert.Equal(t, routes[0].Name, "user")
+	assert.Equal(t, routes[0].Handler, "github.com/vanng822/r2router.routesTestHandler")
+	assert.Equal(t, routes[0].Meta, map[string]interface{}{"auth": true})
+
+	assert.Equal(t, routes[1].Method, "POST")
+	assert.Equal(t, routes[1].Params, []string{"id", "filepath"})
+	assert.Equal(t, routes[1].Name, "")
+	assert.Nil(t, routes[1].Meta)
+
+	assert.Equal(t, routes[2].Pattern, "/api/:version/:name.:ext")
+	assert.Equal(t, routes[2].Params, []string{"version", "name", "ext"})
+	assert.Equal(t, routes[2].Name, "api")
+
+	assert.Equal(t, routes[3].Pattern, "/")
+	assert.Equal(t, routes[3].Params, []string{})
+	assert.Equal(t, routes[3].Handler, "github.com/vanng822/r2router.TestRouterRoutes.func2")
+
+	router.RemoveHandler("GET", "/users/:id")
+	assert.Equal(t, len(router.Routes()), 3)
+	// snapshot is not changed
+	assert.Equal(t, routes[0].Pattern, "/users/:id")
+}
+
+func TestRouteMeta(t *testing.T) {
+	router := NewRouter()
+	route := router.Get("/users/:id", routesTestHandler).Meta("auth", true)
+	routes := router.Routes()
+	route.Meta("role", "admin")
+	assert.Equal(t, routes[0].Meta, map[string]interface{}{"auth": true})
+	assert.Equal(t, router.Routes()[0].Meta, map[string]interface{}{"auth": true, "role": "admin"})
+
+	// kept on replace
+	router.Replace("GET", "/users/:id", func(w http.ResponseWriter, req *http.Request, p Params) {})
+	routes = router.Routes()
+	assert.Equal(t, len(routes), 1)
+	assert.Equal(t, routes[0].Meta, map[string]interface{}{"auth": true, "role": "admin"})
+}
+
+type routesTestStruct struct{}
+
+func (h routesTestStruct) ServeHTTP(w http.ResponseWriter, req *http.Request, p Params) {}
+
+func TestHandlerName(t *testing.T) {
+	assert.Equal(t, handlerName(HandlerFunc(routesTestHandler)), "github.com/vanng822/r2router.routesTestHandler")
+	assert.Equal(t, handlerName(routesTestStruct{}), "r2router.routesTestStruct")
+}
+
+func TestSeeforRoutes(t *testing.T) {
+	seefor := NewSeeforRouter()
+	seefor.Get("/users/:id", routesTestHandler).Name("user")
+	routes := seefor.Routes()
+	assert.Equal(t, len(routes), 1)
+	assert.Equal(t, routes[0].Name, "user")
+	assert.Equal(t, routes[0].Params, []string{"id"})
+}
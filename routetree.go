@@ -5,14 +5,39 @@ import (
 	"strings"
 )
 
+// routeNode is a node in a radix tree of path segments.
+// Static nodes are compressed so a chain of static segments
+// without branches is one node, such as "user/keys"
 type routeNode struct {
-	paramNode  bool
-	paramName  string
-	path       string
-	children   []*routeNode
-	paramChild *routeNode
-	handler    Handler
-	routePath  string
+	paramNode    bool
+	wildcardNode bool
+	patternNode  bool
+	paramName    string
+	constraint   string
+	matcher      Constraint
+	optional     bool
+	defaultValue string
+	// path is the static segments from the parent
+	// or the segment itself for a pattern node
+	path  string
+	parts []segmentPart
+	// indices holds the first byte of each static child
+	// for a quick lookup before comparing the whole path
+	indices         []byte
+	children        []*routeNode
+	patternChildren []*routeNode
+	paramChildren   []*routeNode
+	wildcardChild   *routeNode
+	routePath       string
+	// leaves holds the routes of the node, the one
+	// registered without and with a trailing slash
+	leaves [2]*leaf
+	// pattern is a registered route through the node,
+	// the one of a leaf if it has one
+	pattern string
+	// gen is the generation of the tree the node was created
+	// or copied for, only nodes of that generation are changed
+	gen uint64
 }
 
 func newRouteNode() *routeNode {
@@ -21,46 +46,272 @@ func newRouteNode() *routeNode {
 	return r
 }
 
-// findChild finds a child node that matches the given node
-// It returns nil if no node found. This is to see if
-// we already have a similar node registered
-func (n *routeNode) findChild(nn *routeNode) *routeNode {
-	for _, c := range n.children {
-		if c.path == nn.path {
-			// same node
-			return c
+// leaf is a route registered at a node
+type leaf struct {
+	handler Handler
+	// pattern is the route as registered
+	pattern string
+	// routePath is the path of the node, with the trailing
+	// slash if the route has one, and is the key for timers
+	routePath string
+	// rank is the specificity of the route, see routePattern.rank
+	rank string
+}
+
+// slashLeaf is the index of the route
+// registered with a trailing slash
+const slashLeaf = 1
+
+// matchFlags tell which routes of a node a lookup takes
+type matchFlags uint8
+
+const (
+	// matchSlash is set if the path has a trailing slash
+	matchSlash matchFlags = 1 << iota
+	// matchStrict takes only routes with a trailing
+	// slash if the path has one and the other way around
+	matchStrict
+)
+
+// route returns the route of the node for the lookup, the one with
+// the same trailing slash as the path first. Catch-all routes take
+// any path so the slash is not significant for them
+func (n *routeNode) route(flags matchFlags) *leaf {
+	if n.wildcardNode {
+		return n.leaves[0]
+	}
+	same := 0
+	if flags&matchSlash != 0 {
+		same = slashLeaf
+	}
+	if n.leaves[same] != nil || flags&matchStrict != 0 {
+		return n.leaves[same]
+	}
+	return n.leaves[1-same]
+}
+
+// own returns the node if it belongs to the generation,
+// otherwise a copy for it so the node itself is not changed
+// while it is used by a published tree
+func (n *routeNode) own(gen uint64) *routeNode {
+	if n.gen == gen {
+		return n
+	}
+	c := *n
+	c.gen = gen
+	c.indices = append([]byte(nil), n.indices...)
+	c.children = append([]*routeNode(nil), n.children...)
+	c.patternChildren = append([]*routeNode(nil), n.patternChildren...)
+	c.paramChildren = append([]*routeNode(nil), n.paramChildren...)
+	return &c
+}
+
+// commonSegments returns the length of the longest common
+// prefix of a and b which ends at a segment boundary
+func commonSegments(a, b string) int {
+	max := len(a)
+	if len(b) < max {
+		max = len(b)
+	}
+	i := 0
+	for i < max && a[i] == b[i] {
+		i++
+	}
+	if i == max && (len(a) == i || a[i] == '/') && (len(b) == i || b[i] == '/') {
+		return i
+	}
+	for i > 0 {
+		i--
+		if a[i] == '/' {
+			return i
 		}
 	}
-	return nil
+	return 0
+}
+
+// findStatic finds a static child sharing leading segments
+// with path. It returns the child position and the length
+// of the shared part or -1 if there is no such child
+func (n *routeNode) findStatic(path string) (int, int) {
+	for i, c := range n.indices {
+		if c != path[0] {
+			continue
+		}
+		if l := commonSegments(n.children[i].path, path); l > 0 {
+			return i, l
+		}
+	}
+	return -1, 0
 }
 
-// insertChild registers given node in the route node tree
+// insertStatic registers the static segments in path below this node.
+// An existing node is split at a segment boundary if only a part
+// of it is shared. The returned node is the one for the whole path.
+// The node must belong to gen, nodes changed below it are copied
+func (n *routeNode) insertStatic(path string, gen uint64) *routeNode {
+	for {
+		i, l := n.findStatic(path)
+		if i == -1 {
+			child := newRouteNode()
+			child.gen = gen
+			child.path = path
+			child.routePath = n.routePath + "/" + path
+			n.indices = append(n.indices, path[0])
+			n.children = append(n.children, child)
+			return child
+		}
+		child := n.children[i].own(gen)
+		n.children[i] = child
+		if l < len(child.path) {
+			// split, the first part will be parent of the rest
+			parent := newRouteNode()
+			parent.gen = gen
+			parent.path = child.path[:l]
+			parent.routePath = n.routePath + "/" + parent.path
+			child.path = child.path[l+1:]
+			parent.indices = append(parent.indices, child.path[0])
+			parent.children = append(parent.children, child)
+			n.children[i] = parent
+			child = parent
+		}
+		if l == len(path) {
+			return child
+		}
+		n = child
+		path = path[l+1:]
+	}
+}
+
+// insertChild registers given param, pattern or catch-all node
 // If there is already a similar node it will not insert new node
 // The returned node is always the registered one ie either
-// newly registered or the old one
-func (n *routeNode) insertChild(nn *routeNode) *routeNode {
-	if child := n.findChild(nn); child != nil {
-		return child
+// newly registered or the old one, copied for gen if needed
+func (n *routeNode) insertChild(nn *routeNode, gen uint64) (*routeNode, error) {
+	if nn.wildcardNode {
+		// only one catch-all per node, unique param name
+		if n.wildcardChild != nil {
+			if n.wildcardChild.paramName != nn.paramName {
+				return nil, &RouteError{
+					Existing: n.wildcardChild.pattern,
+					Err:      fmt.Errorf("%w: wildcard name must be same, *%s and *%s", ErrParamConflict, nn.paramName, n.wildcardChild.paramName),
+				}
+			}
+			n.wildcardChild = n.wildcardChild.own(gen)
+			return n.wildcardChild, nil
+		}
+		n.wildcardChild = nn
+		return nn, nil
+	}
+	if nn.patternNode {
+		for i, c := range n.patternChildren {
+			if c.path == nn.path {
+				n.patternChildren[i] = c.own(gen)
+				return n.patternChildren[i], nil
+			}
+		}
+		n.patternChildren = append(n.patternChildren, nn)
+		return nn, nil
 	}
+	return n.insertParamChild(nn, gen)
+}
 
-	if n.paramChild != nil && nn.paramNode {
-		// only allow one param child, unique param name
-		if n.paramChild.paramName != nn.paramName {
-			panic("Param name must be same for")
+// insertParamChild registers a param node. Several params can live
+// at the same position as long as they have different constraints.
+// Constrained params are kept in the order they were registered
+// and the unconstrained one, if any, is always kept last
+func (n *routeNode) insertParamChild(nn *routeNode, gen uint64) (*routeNode, error) {
+	for i, c := range n.paramChildren {
+		if c.constraint != nn.constraint {
+			continue
+		}
+		// only allow one param per constraint, unique param name
+		if c.paramName != nn.paramName {
+			return nil, &RouteError{
+				Existing: c.pattern,
+				Err:      fmt.Errorf("%w: param name must be same, :%s and :%s", ErrParamConflict, nn.paramName, c.paramName),
+			}
+		}
+		if c.optional != nn.optional || c.defaultValue != nn.defaultValue {
+			return nil, &RouteError{
+				Existing: c.pattern,
+				Err:      fmt.Errorf("%w: optional and default value must be same for :%s", ErrParamConflict, nn.paramName),
+			}
 		}
-		return n.paramChild
+		n.paramChildren[i] = c.own(gen)
+		return n.paramChildren[i], nil
 	}
-	if nn.paramNode {
-		n.paramChild = nn
-	} else {
-		n.children = append(n.children, nn)
+	last := len(n.paramChildren) - 1
+	if nn.matcher == nil || last == -1 || n.paramChildren[last].matcher != nil {
+		n.paramChildren = append(n.paramChildren, nn)
+		return nn, nil
+	}
+	// put it before the unconstrained one
+	n.paramChildren = append(n.paramChildren, n.paramChildren[last])
+	n.paramChildren[last] = nn
+	return nn, nil
+}
+
+// empty reports whether the node has neither a route nor children
+func (n *routeNode) empty() bool {
+	return n.leaves == [2]*leaf{} && len(n.children) == 0 && len(n.patternChildren) == 0 &&
+		len(n.paramChildren) == 0 && n.wildcardChild == nil
+}
+
+// removeChild removes the child node, which must be one of its children
+func (n *routeNode) removeChild(child *routeNode) {
+	if n.wildcardChild == child {
+		n.wildcardChild = nil
+		return
+	}
+	for i, c := range n.children {
+		if c == child {
+			n.children = append(n.children[:i], n.children[i+1:]...)
+			n.indices = append(n.indices[:i], n.indices[i+1:]...)
+			return
+		}
+	}
+	for i, c := range n.patternChildren {
+		if c == child {
+			n.patternChildren = append(n.patternChildren[:i], n.patternChildren[i+1:]...)
+			return
+		}
+	}
+	for i, c := range n.paramChildren {
+		if c == child {
+			n.paramChildren = append(n.paramChildren[:i], n.paramChildren[i+1:]...)
+			return
+		}
+	}
+}
+
+// compact merges the static child into its only static child
+// if the child has no route and no other children, as if the
+// routes were added to a new tree
+func (n *routeNode) compact(child *routeNode, gen uint64) {
+	if child.paramNode || child.patternNode || child.wildcardNode || child.leaves != [2]*leaf{} ||
+		len(child.children) != 1 || len(child.patternChildren) > 0 ||
+		len(child.paramChildren) > 0 || child.wildcardChild != nil {
+		return
+	}
+	for i, c := range n.children {
+		if c == child {
+			merged := child.children[0].own(gen)
+			merged.path = child.path + "/" + merged.path
+			n.children[i] = merged
+			return
+		}
 	}
-	return nn
 }
 
 type rootNode struct {
-	root    *routeNode
-	handler Handler
+	root *routeNode
+	// index is the node for /
+	index *leaf
+	// gen is the generation of the tree, nodes of
+	// other generations are copied before changing them
+	gen uint64
+	// routes is the number of routes in the tree
+	routes int
 }
 
 func newRouteTree() *rootNode {
@@ -69,6 +320,15 @@ func newRouteTree() *rootNode {
 	return r
 }
 
+// clone returns a copy of the tree for the generation. Nodes are
+// shared until they are changed, then they are copied, so the
+// tree cloned from can be used for lookups meanwhile
+func (n *rootNode) clone(gen uint64) *rootNode {
+	c := *n
+	c.gen = gen
+	return &c
+}
+
 func nextPath(path string) (string, string) {
 	i := strings.Index(path, "/")
 	if i == -1 {
@@ -77,91 +337,523 @@ func nextPath(path string) (string, string) {
 	return path[:i], path[i+1:]
 }
 
+// nextPattern is as nextPath but does not split
+// on slashes inside a param constraint such as :id([^/]+)
+func nextPattern(path string) (string, string) {
+	depth := 0
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '/':
+			if depth <= 0 {
+				return path[:i], path[i+1:]
+			}
+		}
+	}
+	return path, ""
+}
+
+// braceSegment converts a {name} segment to :name
+// and {name...} to *name, others are returned as they are
+func braceSegment(token string) string {
+	if len(token) < 2 || token[0] != '{' || token[len(token)-1] != '}' {
+		return token
+	}
+	name := token[1 : len(token)-1]
+	if strings.HasSuffix(name, "...") {
+		return "*" + strings.TrimSuffix(name, "...")
+	}
+	return ":" + name
+}
+
+// splitPattern splits a route pattern into its segments
+// the same way as strings.Split, respecting param constraints.
+// Segments in the net/http form are converted, see braceSegment
+func splitPattern(path string) []string {
+	parts := make([]string, 0)
+	for {
+		token, rest := nextPattern(path)
+		parts = append(parts, braceSegment(token))
+		if len(token) == len(path) {
+			return parts
+		}
+		path = rest
+	}
+}
+
+// routePattern is a parsed route pattern,
+// kept with the route so it is parsed once
+type routePattern struct {
+	// pattern is the route as registered
+	pattern  string
+	segments []routeSegment
+	// trailingSlash is set if the route ends with one,
+	// it is not significant if it ends with a catch-all
+	trailingSlash bool
+	// key is the pattern without leading and trailing slashes
+	// and with {name} segments in the :name form
+	key string
+}
+
+// routeSegment is a segment of a route pattern,
+// with {name} segments in the :name form
+type routeSegment struct {
+	token string
+	// parts is nil for a catch-all
+	parts []segmentPart
+}
+
+func (s *routeSegment) static() bool {
+	return len(s.parts) == 1 && !s.parts[0].param
+}
+
+// rank returns the specificity of the route, a byte per segment in
+// the order find tries them: static, static text and params, param
+// with constraint, param and catch-all. Of routes in different trees
+// matching a path the one with the lower rank is the more specific,
+// as find would have taken it if they were in the same tree
+func (rp *routePattern) rank() string {
+	rank := make([]byte, len(rp.segments))
+	for i, seg := range rp.segments {
+		switch {
+		case seg.parts == nil:
+			rank[i] = '4'
+		case seg.static():
+			rank[i] = '0'
+		case len(seg.parts) > 1:
+			rank[i] = '1'
+		case seg.parts[0].matcher != nil:
+			rank[i] = '2'
+		default:
+			rank[i] = '3'
+		}
+	}
+	return string(rank)
+}
+
+// leafIndex returns the index of the route in the leaves of its node
+func (rp *routePattern) leafIndex() int {
+	if rp.trailingSlash && len(rp.segments) > 0 && rp.segments[len(rp.segments)-1].parts != nil {
+		return slashLeaf
+	}
+	return 0
+}
+
+// parseRoute parses the route pattern. It returns a *RouteError
+// if the pattern is invalid, such as an empty segment
+func parseRoute(pattern string) (*routePattern, error) {
+	rp := &routePattern{pattern: pattern}
+	rp.trailingSlash = len(pattern) > 1 && pattern[len(pattern)-1] == '/'
+	rest := strings.Trim(pattern, "/")
+	var token string
+	for rest != "" {
+		token, rest = nextPattern(rest)
+		token = braceSegment(token)
+		if token == "" {
+			return nil, &RouteError{Pattern: pattern, Err: fmt.Errorf("%w: empty segment", ErrInvalidRoute)}
+		}
+		seg := routeSegment{token: token}
+		if token[:1] == "*" {
+			if strings.TrimSpace(token[1:]) == "" {
+				return nil, &RouteError{Pattern: pattern, Err: fmt.Errorf("%w: wildcard name can not be empty", ErrInvalidRoute)}
+			}
+			if rest != "" {
+				return nil, &RouteError{Pattern: pattern, Err: fmt.Errorf("%w: wildcard must be the last segment", ErrInvalidRoute)}
+			}
+		} else {
+			var err error
+			if seg.parts, err = parseSegment(token); err != nil {
+				return nil, &RouteError{Pattern: pattern, Err: err}
+			}
+		}
+		rp.segments = append(rp.segments, seg)
+		if rp.key != "" {
+			rp.key += "/"
+		}
+		rp.key += token
+	}
+	return rp, nil
+}
+
+// addRoute registers the handler for the route pattern
+// and panics if the route can not be registered,
+// the trailing slash is not significant
 func (n *rootNode) addRoute(path string, handler Handler) {
-	path = strings.Trim(path, "/")
-	if path != "" {
-		// Start with the roots
-		parent := n.root
-		var token string
-		for {
-			if path == "" {
-				break
+	if err := n.tryAddRoute(path, handler); err != nil {
+		panic(err)
+	}
+}
+
+// tryAddRoute registers the handler for the route pattern.
+// It returns a *RouteError if the route can not be registered,
+// the tree may be partly changed then and should be dropped
+func (n *rootNode) tryAddRoute(path string, handler Handler) error {
+	rp, err := parseRoute(path)
+	if err != nil {
+		return err
+	}
+	return n.insert(rp, handler, false)
+}
+
+// insert registers the handler for the parsed route. If strict is set
+// a route with a trailing slash is another route than the one without.
+// Nodes of other generations than the tree are copied before changing.
+// It returns a *RouteError if the route can not be registered,
+// the tree may be partly changed then and should be dropped
+func (n *rootNode) insert(rp *routePattern, handler Handler, strict bool) error {
+	pattern := rp.pattern
+	if len(rp.segments) == 0 {
+		if n.index != nil {
+			return &RouteError{Pattern: pattern, Existing: n.index.pattern, Err: ErrDuplicateRoute}
+		}
+		n.index = &leaf{handler: handler, pattern: pattern, routePath: "/", rank: rp.rank()}
+		n.routes++
+		return nil
+	}
+	// Start with the roots
+	n.root = n.root.own(n.gen)
+	parent := n.root
+	// consecutive static segments are inserted at once
+	var static string
+	for _, seg := range rp.segments {
+		if seg.static() {
+			if static != "" {
+				static += "/"
 			}
-			child := newRouteNode()
-			token, path = nextPath(path)
-			//fmt.Println(token, path)
-			if token[:1] == ":" {
-				// param type
-				child.paramName = strings.TrimSpace(token[1:])
-				if child.paramName == "" {
-					panic("Param name can not be empty")
+			static += seg.token
+			continue
+		}
+		if static != "" {
+			parent = parent.insertStatic(static, n.gen)
+			static = ""
+		}
+		child := newRouteNode()
+		child.gen = n.gen
+		child.pattern = pattern
+		if seg.parts == nil {
+			// catch-all type, takes the rest of the path
+			child.paramName = strings.TrimSpace(seg.token[1:])
+			child.wildcardNode = true
+		} else if len(seg.parts) == 1 {
+			// param type, with optional constraint
+			part := seg.parts[0]
+			child.paramName = part.text
+			child.constraint = part.constraint
+			child.matcher = part.matcher
+			child.optional = part.optional
+			child.defaultValue = part.defaultValue
+			child.paramNode = true
+		} else {
+			// static text and params in one segment
+			child.path = seg.token
+			child.parts = seg.parts
+			child.patternNode = true
+		}
+		// will be parent for the next path token
+		child.routePath = fmt.Sprintf("%s/%s", parent.routePath, seg.token)
+		var err error
+		if parent, err = parent.insertChild(child, n.gen); err != nil {
+			err.(*RouteError).Pattern = pattern
+			return err
+		}
+	}
+	if static != "" {
+		parent = parent.insertStatic(static, n.gen)
+	}
+	// adding handler
+	i := rp.leafIndex()
+	for j, existing := range parent.leaves {
+		if existing != nil && (j == i || !strict) {
+			return &RouteError{Pattern: pattern, Existing: existing.pattern, Err: ErrDuplicateRoute}
+		}
+	}
+	routePath := parent.routePath
+	if i == slashLeaf {
+		routePath += "/"
+	}
+	parent.leaves[i] = &leaf{handler: handler, pattern: pattern, routePath: routePath, rank: rp.rank()}
+	parent.pattern = pattern
+	n.routes++
+	return nil
+}
+
+// remove removes the route registered with the parsed pattern and
+// reports whether there was one. Nodes left without routes are
+// removed, nodes of other generations are copied before changing
+func (n *rootNode) remove(rp *routePattern) bool {
+	if len(rp.segments) == 0 {
+		if n.index == nil {
+			return false
+		}
+		n.index = nil
+		n.routes--
+		return true
+	}
+	n.root = n.root.own(n.gen)
+	node := n.root
+	nodes := []*routeNode{node}
+	for i := 0; i < len(rp.segments); i++ {
+		seg := rp.segments[i]
+		var slot **routeNode
+		switch {
+		case seg.static():
+			// consecutive static segments can be split over nodes
+			static := seg.token
+			for i+1 < len(rp.segments) && rp.segments[i+1].static() {
+				i++
+				static += "/" + rp.segments[i].token
+			}
+			for {
+				j, l := node.findStatic(static)
+				if j == -1 || l < len(node.children[j].path) {
+					return false
 				}
-				child.paramNode = true
-			} else {
-				child.path = token
-			}
-			// will be parent for the next path token
-			child.routePath = fmt.Sprintf("%s/%s", parent.routePath, token)
-			parent = parent.insertChild(child)
-		}
-		// adding handler
-		if parent.handler != nil {
-			panic(fmt.Sprintf("'%s' has already a handler", path))
-		}
-		parent.handler = handler
-
-	} else {
-		if n.handler != nil {
-			panic("There is already a handler for /")
-		}
-		n.handler = handler
-	}
-}
-
-func (n *rootNode) match(path string) (Handler, Params, string) {
-	path = strings.Trim(path, "/")
-	params := &params_{}
-	params.appData = make(map[interface{}]interface{})
-	params.requestParams = make(map[string]string)
-	if path != "" {
-		// can be better by getting one at the time
-		var matched bool
-		var token string
-		route := n.root
-		for {
-			if path == "" {
-				break
-			}
-			token, path = nextPath(path)
-			matched = false
-			for _, c := range route.children {
-				if c.path == token {
-					route = c
-					matched = true
+				node.children[j] = node.children[j].own(n.gen)
+				node = node.children[j]
+				nodes = append(nodes, node)
+				if l == len(static) {
 					break
 				}
+				static = static[l+1:]
+			}
+			continue
+		case seg.parts == nil:
+			if node.wildcardChild != nil {
+				slot = &node.wildcardChild
+			}
+		case len(seg.parts) == 1:
+			for j, c := range node.paramChildren {
+				if c.constraint == seg.parts[0].constraint && c.paramName == seg.parts[0].text {
+					slot = &node.paramChildren[j]
+				}
+			}
+		default:
+			for j, c := range node.patternChildren {
+				if c.path == seg.token {
+					slot = &node.patternChildren[j]
+				}
+			}
+		}
+		if slot == nil {
+			return false
+		}
+		*slot = (*slot).own(n.gen)
+		node = *slot
+		nodes = append(nodes, node)
+	}
+	if node.leaves[rp.leafIndex()] == nil {
+		return false
+	}
+	node.leaves[rp.leafIndex()] = nil
+	n.routes--
+	// remove nodes left empty, then merge a static node
+	// left with one static child as insertStatic would
+	k := len(nodes) - 1
+	for ; k > 0 && nodes[k].empty(); k-- {
+		nodes[k-1].removeChild(nodes[k])
+	}
+	if k > 0 {
+		nodes[k-1].compact(nodes[k], n.gen)
+	}
+	return true
+}
+
+// find looks up the node with a handler for the given path,
+// which is relative to this node and has no leading slash.
+// Children are tried in priority order and the matcher backtracks
+// if a deeper path fails, so a static child never shadows a param:
+//  1. static children
+//  2. pattern children, mixing static text and params,
+//     in the order they were registered
+//  3. param children, constrained ones in the order they were
+//     registered and then the unconstrained one
+//  4. optional param children as if their segment was absent
+//  5. the catch-all child, which takes the rest of the path
+//
+// A node without a route for the flags, such as one for the other
+// trailing slash in strict mode, is a failed branch too.
+// Params of failed branches are removed before trying the next one.
+// Static segments are compared regardless of ASCII case if fold is given
+func (n *routeNode) find(path string, params *params_, fold *foldState, flags matchFlags) *leaf {
+	if atEnd(path) {
+		if route := n.route(flags); route != nil {
+			return route
+		}
+		if route := n.skipOptional(path, params, fold, flags); route != nil {
+			return route
+		}
+		if n.wildcardChild != nil {
+			if route := n.wildcardChild.route(flags); route != nil {
+				// empty rest matches catch-all too
+				params.push(n.wildcardChild.paramName, "")
+				return route
 			}
+		}
+		return nil
+	}
 
-			if !matched {
-				if route.paramChild != nil {
-					route = route.paramChild
-					matched = true
-					params.requestParams[route.paramName] = token
-					continue
+	for i, c := range n.indices {
+		if c != path[0] && (fold == nil || lowerASCII(c) != lowerASCII(path[0])) {
+			continue
+		}
+		child := n.children[i]
+		l := len(child.path)
+		if len(path) < l || (len(path) > l && path[l] != '/') {
+			continue
+		}
+		if fold == nil {
+			if path[:l] != child.path {
+				continue
+			}
+		} else if !equalFoldASCII(path[:l], child.path) {
+			continue
+		}
+		rest := ""
+		if len(path) > l {
+			rest = path[l+1:]
+		}
+		fold.set(path, child.path)
+		if route := child.find(rest, params, fold, flags); route != nil {
+			return route
+		}
+		fold.set(path, path[:l])
+		if fold == nil {
+			// only one can match exactly
+			break
+		}
+	}
+
+	if len(n.patternChildren) > 0 || len(n.paramChildren) > 0 {
+		token, rest := nextPath(path)
+		mark := params.Len()
+		for _, c := range n.patternChildren {
+			if matchParts(c.parts, token, params) {
+				if route := c.find(rest, params, fold, flags); route != nil {
+					return route
 				}
-				return nil, nil, ""
+				params.pop(mark)
 			}
 		}
-		return route.handler, params, route.routePath
-	} else {
-		return n.handler, params, "/"
+		for _, c := range n.paramChildren {
+			if c.matcher == nil || c.matcher(token) {
+				params.push(c.paramName, token)
+				if route := c.find(rest, params, fold, flags); route != nil {
+					return route
+				}
+				params.pop(mark)
+			}
+		}
+	}
+
+	if route := n.skipOptional(path, params, fold, flags); route != nil {
+		return route
+	}
+
+	if n.wildcardChild != nil {
+		if route := n.wildcardChild.route(flags); route != nil {
+			// catch-all gets the rest, slashes included
+			params.push(n.wildcardChild.paramName, path)
+			return route
+		}
+	}
+	return nil
+}
+
+// skipOptional looks up the path below optional param children
+// as if their segment was absent, their default values are used
+func (n *routeNode) skipOptional(path string, params *params_, fold *foldState, flags matchFlags) *leaf {
+	mark := params.Len()
+	for _, c := range n.paramChildren {
+		if !c.optional {
+			continue
+		}
+		if c.defaultValue != "" {
+			params.push(c.paramName, c.defaultValue)
+		}
+		if route := c.find(path, params, fold, flags); route != nil {
+			return route
+		}
+		params.pop(mark)
 	}
+	return nil
 }
 
+// match returns the route for the given path or nil if there is none.
+// Matched params are added to params, which can be nil
+// if one only needs to know whether there is a handler.
+// A case-insensitive match is done if fold is given.
+// The trailing slash must be as registered if flags has matchStrict
+func (n *rootNode) match(path string, params *params_, fold *foldState, flags matchFlags) *leaf {
+	if len(path) > 1 && path[len(path)-1] == '/' {
+		flags |= matchSlash
+	}
+	// trailing slashes are kept for a catch-all
+	path = strings.TrimLeft(path, "/")
+	if atEnd(path) && n.index != nil {
+		return n.index
+	}
+	if fold != nil && fold.fix {
+		fold.path = []byte(path)
+	}
+	return n.root.find(path, params, fold, flags)
+}
+
+// atEnd reports whether path has nothing but trailing slashes left
+func atEnd(path string) bool {
+	for i := 0; i < len(path); i++ {
+		if path[i] != '/' {
+			return false
+		}
+	}
+	return true
+}
+
+// foldState is for case-insensitive lookups. If fix is set
+// path will be the matched path, without leading slashes,
+// with static segments in the case they were registered
+type foldState struct {
+	fix  bool
+	path []byte
+}
+
+// set writes s to the fixed path at the position where path starts,
+// path is always the end of the path being matched
+func (f *foldState) set(path, s string) {
+	if f != nil && f.path != nil {
+		copy(f.path[len(f.path)-len(path):], s)
+	}
+}
+
+func lowerASCII(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// equalFoldASCII is strings.EqualFold for ASCII only
+// so that the length never changes
+func equalFoldASCII(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a); i++ {
+		if lowerASCII(a[i]) != lowerASCII(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// dump returns the tree as text with children in the same
+// order as export, the handler of / is shown on the root
 func (n *rootNode) dump() string {
-	var dumNode func(node *routeNode, ident int) string
+	var dumNode func(node *TreeNode, ident int) string
 
-	dumNode = func(node *routeNode, ident int) string {
+	dumNode = func(node *TreeNode, ident int) string {
 		s := ""
 		identing := ""
 		for i := 0; i < ident; i++ {
@@ -169,24 +861,19 @@ func (n *rootNode) dump() string {
 		}
 		s += identing + " |\n"
 		identing += "  "
-		if node.paramNode {
-			s += identing + "-- :" + node.paramName
-		} else {
-			s += identing + "-- " + node.path
-		}
-		if node.handler != nil {
-			s += fmt.Sprintf(" (<%p>)", &node.handler)
+		s += identing + "-- " + node.Path
+		if node.Handler != "" {
+			s += fmt.Sprintf(" (<%s>)", node.Handler)
 		}
 		s += "\n"
 
-		for _, c := range node.children {
+		for _, c := range node.Children {
 			s += dumNode(c, ident+1)
 		}
-		if node.paramChild != nil {
-			s += dumNode(node.paramChild, ident+1)
-		}
 		return s
 	}
 
-	return dumNode(n.root, 0)
+	tree := n.export()
+	tree.Path = ""
+	return dumNode(tree, 0)
 }
@@ -5,6 +5,15 @@ import (
 	"testing"
 )
 
+// testMatch is match with new params
+func testMatch(r *rootNode, path string) (Handler, *params_, string) {
+	p := &params_{}
+	if route := r.match(path, p, nil, 0); route != nil {
+		return route.handler, p, route.routePath
+	}
+	return nil, p, ""
+}
+
 func TestAddRouteDuplicate(t *testing.T) {
 	r := newRouteTree()
 	assert.Panics(t, func() {
@@ -32,7 +41,7 @@ func TestAddRouteBrokenParamName(t *testing.T) {
 func TestAddRouteIndex(t *testing.T) {
 	r := newRouteTree()
 	r.addRoute("/", &httpTestHandler{})
-	assert.NotNil(t, r.handler)
+	assert.NotNil(t, r.index.handler)
 	assert.Panics(t, func() {
 		r.addRoute("/", &httpTestHandler{})
 	})
@@ -41,12 +50,10 @@ func TestAddRouteIndex(t *testing.T) {
 func TestMatchTrue(t *testing.T) {
 	r := newRouteTree()
 	r.addRoute("/users/:user/events", &httpTestHandler{})
-	h, p, route := r.match("/users/vanng822/events")
+	h, p, route := testMatch(r, "/users/vanng822/events")
 	assert.NotNil(t, h)
 	exectedP := &params_{}
-	exectedP.appData = make(map[interface{}]interface{})
-	exectedP.requestParams = make(map[string]string)
-	exectedP.requestParams["user"] = "vanng822"
+	exectedP.requestParams = []param{{"user", "vanng822"}}
 	assert.Equal(t, p, exectedP)
 	
 	assert.Equal(t, route, "/users/:user/events")
@@ -55,7 +62,7 @@ func TestMatchTrue(t *testing.T) {
 func TestMatchIndex(t *testing.T) {
 	r := newRouteTree()
 	r.addRoute("/", &httpTestHandler{})
-	h, p, route := r.match("/")
+	h, p, route := testMatch(r, "/")
 	assert.NotNil(t, h)
 	assert.NotNil(t, p)
 	assert.Equal(t, route, "/")
@@ -64,18 +71,382 @@ func TestMatchIndex(t *testing.T) {
 func TestMatchIndexNil(t *testing.T) {
 	r := newRouteTree()
 	r.addRoute("/testing", &httpTestHandler{})
-	h, p, route := r.match("/")
+	h, p, route := testMatch(r, "/")
 	assert.Nil(t, h)
-	assert.NotNil(t, p)
-	assert.Equal(t, route, "/")
+	assert.Equal(t, len(p.requestParams), 0)
+	assert.Equal(t, route, "")
 }
 
 func TestMatchFalse(t *testing.T) {
 	r := newRouteTree()
 	r.addRoute("/users/:user/events", &httpTestHandler{})
-	h, p, route := r.match("/users/:user/orgs")
+	h, p, route := testMatch(r, "/users/:user/orgs")
 	assert.Nil(t, h)
-	assert.Nil(t, p)
+	assert.Equal(t, len(p.requestParams), 0)
 	assert.Equal(t, route, "")
 }
 
+
+func TestAddRouteWildcard(t *testing.T) {
+	r := newRouteTree()
+	r.addRoute("/static/*filepath", &httpTestHandler{})
+	assert.NotNil(t, r.root.children[0].wildcardChild)
+	assert.Equal(t, r.root.children[0].wildcardChild.paramName, "filepath")
+	assert.Equal(t, r.root.children[0].wildcardChild.routePath, "/static/*filepath")
+}
+
+func TestAddRouteWildcardBroken(t *testing.T) {
+	r := newRouteTree()
+	assert.Panics(t, func() {
+		r.addRoute("/static/*", &httpTestHandler{})
+	})
+	assert.Panics(t, func() {
+		r.addRoute("/static/*filepath/more", &httpTestHandler{})
+	})
+	r.addRoute("/proxy/*rest", &httpTestHandler{})
+	assert.Panics(t, func() {
+		r.addRoute("/proxy/*other", &httpTestHandler{})
+	})
+}
+
+func TestMatchWildcard(t *testing.T) {
+	r := newRouteTree()
+	r.addRoute("/static/*filepath", &httpTestHandler{})
+	r.addRoute("/static/favicon.ico", &httpTestHandler{})
+	r.addRoute("/proxy/:host/*rest", &httpTestHandler{})
+
+	h, p, route := testMatch(r, "/static/css/app/main.css")
+	assert.NotNil(t, h)
+	assert.Equal(t, p.Get("filepath"), "css/app/main.css")
+	assert.Equal(t, route, "/static/*filepath")
+
+	h, p, route = testMatch(r, "/static/favicon.ico")
+	assert.NotNil(t, h)
+	assert.False(t, p.Has("filepath"))
+	assert.Equal(t, route, "/static/favicon.ico")
+
+	h, p, route = testMatch(r, "/static/")
+	assert.NotNil(t, h)
+	assert.True(t, p.Has("filepath"))
+	assert.Equal(t, p.Get("filepath"), "")
+	assert.Equal(t, route, "/static/*filepath")
+
+	h, p, route = testMatch(r, "/proxy/example.com/api/v1/users")
+	assert.NotNil(t, h)
+	assert.Equal(t, p.Get("host"), "example.com")
+	assert.Equal(t, p.Get("rest"), "api/v1/users")
+	assert.Equal(t, route, "/proxy/:host/*rest")
+
+	// trailing slashes are kept
+	h, p, route = testMatch(r, "/static/css/app/")
+	assert.NotNil(t, h)
+	assert.Equal(t, p.Get("filepath"), "css/app/")
+	h, p, route = testMatch(r, "/proxy/example.com/api//")
+	assert.NotNil(t, h)
+	assert.Equal(t, p.Get("host"), "example.com")
+	assert.Equal(t, p.Get("rest"), "api//")
+	h, p, route = testMatch(r, "/proxy/example.com/")
+	assert.NotNil(t, h)
+	assert.Equal(t, p.Get("rest"), "")
+}
+
+func TestMatchWildcardRoot(t *testing.T) {
+	r := newRouteTree()
+	r.addRoute("/*all", &httpTestHandler{})
+	h, p, route := testMatch(r, "/")
+	assert.NotNil(t, h)
+	assert.Equal(t, p.Get("all"), "")
+	assert.Equal(t, route, "/*all")
+
+	h, p, _ = testMatch(r, "/any/thing")
+	assert.NotNil(t, h)
+	assert.Equal(t, p.Get("all"), "any/thing")
+}
+
+func TestAddRouteConstrainedParams(t *testing.T) {
+	r := newRouteTree()
+	r.addRoute("/users/:name", &httpTestHandler{})
+	r.addRoute(`/users/:id(\d+)`, &httpTestHandler{})
+	r.addRoute("/users/:uid<uuid>", &httpTestHandler{})
+	r.addRoute(`/users/:id(\d+)/events`, &httpTestHandler{})
+	users := r.root.children[0]
+	assert.Equal(t, len(users.paramChildren), 3)
+	assert.Equal(t, users.paramChildren[0].paramName, "id")
+	assert.Equal(t, users.paramChildren[1].paramName, "uid")
+	// unconstrained always last
+	assert.Equal(t, users.paramChildren[2].paramName, "name")
+
+	assert.Panics(t, func() {
+		r.addRoute("/users/:other<uuid>/events", &httpTestHandler{})
+	})
+	assert.Panics(t, func() {
+		r.addRoute("/users/:other", &httpTestHandler{})
+	})
+}
+
+func TestMatchConstrainedParams(t *testing.T) {
+	r := newRouteTree()
+	r.addRoute(`/users/:id(\d+)`, &httpTestHandler{})
+	r.addRoute("/users/:uid<uuid>", &httpTestHandler{})
+	r.addRoute("/users/:name/events", &httpTestHandler{})
+	r.addRoute("/files/:path([^/]+/[^/]+)", &httpTestHandler{})
+
+	h, p, route := testMatch(r, "/users/42")
+	assert.NotNil(t, h)
+	assert.Equal(t, p.Get("id"), "42")
+	assert.Equal(t, route, `/users/:id(\d+)`)
+
+	h, p, route = testMatch(r, "/users/0e8b4f0a-9a3c-4c5d-8f7e-1a2b3c4d5e6f")
+	assert.NotNil(t, h)
+	assert.Equal(t, p.Get("uid"), "0e8b4f0a-9a3c-4c5d-8f7e-1a2b3c4d5e6f")
+	assert.Equal(t, route, "/users/:uid<uuid>")
+
+	h, _, _ = testMatch(r, "/users/vanng822")
+	assert.Nil(t, h)
+
+	h, p, route = testMatch(r, "/users/vanng822/events")
+	assert.NotNil(t, h)
+	assert.Equal(t, p.Get("name"), "vanng822")
+	assert.Equal(t, route, "/users/:name/events")
+
+	// constraint does not make a token span several segments
+	h, _, _ = testMatch(r, "/files/a/b")
+	assert.Nil(t, h)
+}
+
+func TestAddRouteCompressed(t *testing.T) {
+	r := newRouteTree()
+	r.addRoute("/user/keys/list", &httpTestHandler{})
+	assert.Equal(t, len(r.root.children), 1)
+	assert.Equal(t, r.root.children[0].path, "user/keys/list")
+
+	// split at segment boundary
+	r.addRoute("/user/emails", &httpTestHandler{})
+	user := r.root.children[0]
+	assert.Equal(t, user.path, "user")
+	assert.Nil(t, user.leaves[0])
+	assert.Equal(t, user.children[0].path, "keys/list")
+	assert.Equal(t, user.children[0].routePath, "/user/keys/list")
+	assert.Equal(t, user.children[1].path, "emails")
+	assert.Equal(t, string(user.indices), "ke")
+
+	// same first byte but another segment
+	r.addRoute("/users", &httpTestHandler{})
+	assert.Equal(t, len(r.root.children), 2)
+	assert.Equal(t, string(r.root.indices), "uu")
+
+	r.addRoute("/user/keys/:id", &httpTestHandler{})
+	keys := user.children[0]
+	assert.Equal(t, keys.path, "keys")
+	assert.Equal(t, keys.routePath, "/user/keys")
+	assert.Equal(t, keys.children[0].path, "list")
+	assert.Equal(t, keys.paramChildren[0].routePath, "/user/keys/:id")
+
+	r.addRoute("/user", &httpTestHandler{})
+	assert.NotNil(t, user.leaves[0])
+
+	for _, path := range []string{"/user/keys/list", "/user/emails", "/users", "/user/keys/1", "/user"} {
+		h, _, route := testMatch(r, path)
+		assert.NotNil(t, h, path)
+		assert.NotEqual(t, route, "", path)
+	}
+	for _, path := range []string{"/user/keys", "/user/key", "/use", "/user/keys/list/more", "/user/emailss"} {
+		h, _, _ := testMatch(r, path)
+		assert.Nil(t, h, path)
+	}
+}
+
+func TestRemoveRoute(t *testing.T) {
+	routes := []string{"/user/keys/list", "/user/emails", "/users", "/user/keys/:id",
+		"/user", "/user/:id/files/*filepath", "/files/:name.:ext", "/"}
+	r := newRouteTree()
+	for _, path := range routes {
+		r.addRoute(path, &httpTestHandler{})
+	}
+	for i, path := range routes {
+		rp, err := parseRoute(path)
+		assert.Nil(t, err)
+		assert.True(t, r.remove(rp), path)
+		assert.False(t, r.remove(rp), path)
+		// same tree as if the other routes were added only
+		fresh := newRouteTree()
+		for _, rest := range routes[i+1:] {
+			fresh.addRoute(rest, &httpTestHandler{})
+		}
+		assert.Equal(t, r.dump(), fresh.dump(), path)
+		assert.Equal(t, r.routes, len(routes)-i-1)
+	}
+
+	r.addRoute("/user/keys/list", &httpTestHandler{})
+	for _, path := range []string{"/user", "/user/keys", "/user/keys/list/more", "/user/:id/keys"} {
+		rp, _ := parseRoute(path)
+		assert.False(t, r.remove(rp), path)
+	}
+}
+
+func TestAddRouteCopyOnWrite(t *testing.T) {
+	r := newRouteTree()
+	r.addRoute("/user/keys/list", &httpTestHandler{})
+	r.addRoute("/files/:name", &httpTestHandler{})
+	before := r.dump()
+
+	c := r.clone(1)
+	c.addRoute("/user/emails", &httpTestHandler{})
+	rp, _ := parseRoute("/files/:name")
+	assert.True(t, c.remove(rp))
+	assert.Equal(t, r.dump(), before)
+	assert.Equal(t, r.routes, 2)
+	assert.Equal(t, c.routes, 2)
+	h, _, _ := testMatch(c, "/user/emails")
+	assert.NotNil(t, h)
+	h, _, _ = testMatch(c, "/files/a")
+	assert.Nil(t, h)
+
+	// nodes not on the changed paths are shared
+	c = r.clone(2)
+	c.addRoute("/files/:name/meta", &httpTestHandler{})
+	assert.True(t, c.root.children[0] == r.root.children[0])
+	assert.False(t, c.root.children[1] == r.root.children[1])
+}
+
+func TestCommonSegments(t *testing.T) {
+	assert.Equal(t, commonSegments("user", "users"), 0)
+	assert.Equal(t, commonSegments("user", "user"), 4)
+	assert.Equal(t, commonSegments("user", "user/keys"), 4)
+	assert.Equal(t, commonSegments("user/keys", "user/emails"), 4)
+	assert.Equal(t, commonSegments("a/b/c", "a/b/cd"), 3)
+	assert.Equal(t, commonSegments("events", "user"), 0)
+}
+
+func TestMatchNoAllocs(t *testing.T) {
+	r := newRouteTree()
+	for _, route := range githubAPI {
+		if route[0] == "GET" {
+			r.addRoute(route[1], &httpTestHandler{})
+		}
+	}
+	p := acquireParams()
+	defer releaseParams(p)
+	allocs := testing.AllocsPerRun(100, func() {
+		r.match("/repos/vanng822/r2router/collaborators/vanng822", p, nil, 0)
+		p.reset()
+		r.match("/user/keys", p, nil, 0)
+		p.reset()
+	})
+	assert.Equal(t, allocs, float64(0))
+}
+
+func TestMatchMixedSegments(t *testing.T) {
+	r := newRouteTree()
+	r.addRoute("/files/:name.:ext", &httpTestHandler{})
+	r.addRoute("/files/:name", &httpTestHandler{})
+	r.addRoute("/v:version/users", &httpTestHandler{})
+	r.addRoute("/@:username", &httpTestHandler{})
+	r.addRoute("/:page", &httpTestHandler{})
+
+	h, p, route := testMatch(r, "/files/report.pdf")
+	assert.NotNil(t, h)
+	assert.Equal(t, route, "/files/:name.:ext")
+	assert.Equal(t, p.Get("name"), "report")
+	assert.Equal(t, p.Get("ext"), "pdf")
+
+	h, p, route = testMatch(r, "/files/README")
+	assert.NotNil(t, h)
+	assert.Equal(t, route, "/files/:name")
+	assert.Equal(t, p.Get("name"), "README")
+
+	h, p, route = testMatch(r, "/v2/users")
+	assert.NotNil(t, h)
+	assert.Equal(t, route, "/v:version/users")
+	assert.Equal(t, p.Get("version"), "2")
+
+	h, p, route = testMatch(r, "/@vanng822")
+	assert.NotNil(t, h)
+	assert.Equal(t, route, "/@:username")
+	assert.Equal(t, p.Get("username"), "vanng822")
+
+	// pattern matched but the rest did not
+	h, p, route = testMatch(r, "/v2")
+	assert.NotNil(t, h)
+	assert.Equal(t, route, "/:page")
+	assert.Equal(t, p.requestParams, []param{{"page", "v2"}})
+}
+
+func TestMatchOptional(t *testing.T) {
+	r := newRouteTree()
+	r.addRoute("/docs/:lang?=en/:page", &httpTestHandler{})
+	r.addRoute(`/reports/:year/:month(\d{2})?`, &httpTestHandler{})
+
+	h, p, route := testMatch(r, "/docs/sv/intro")
+	assert.NotNil(t, h)
+	assert.Equal(t, route, "/docs/:lang?=en/:page")
+	assert.Equal(t, p.requestParams, []param{{"lang", "sv"}, {"page", "intro"}})
+
+	h, p, route = testMatch(r, "/docs/intro")
+	assert.NotNil(t, h)
+	assert.Equal(t, route, "/docs/:lang?=en/:page")
+	assert.Equal(t, p.requestParams, []param{{"lang", "en"}, {"page", "intro"}})
+
+	h, p, route = testMatch(r, "/reports/2015/05")
+	assert.NotNil(t, h)
+	assert.Equal(t, route, `/reports/:year/:month(\d{2})?`)
+	assert.Equal(t, p.Get("month"), "05")
+
+	h, p, route = testMatch(r, "/reports/2015")
+	assert.NotNil(t, h)
+	assert.Equal(t, route, `/reports/:year/:month(\d{2})?`)
+	assert.Equal(t, p.Get("year"), "2015")
+	assert.False(t, p.Has("month"))
+
+	h, _, _ = testMatch(r, "/reports/2015/may")
+	assert.Nil(t, h)
+	h, _, _ = testMatch(r, "/docs")
+	assert.Nil(t, h)
+}
+
+func TestAddRouteOptionalConflict(t *testing.T) {
+	r := newRouteTree()
+	r.addRoute("/reports/:year/:month?", &httpTestHandler{})
+	assert.Panics(t, func() {
+		r.addRoute("/reports/:year/:month/days", &httpTestHandler{})
+	})
+	assert.Panics(t, func() {
+		r.addRoute("/reports/:year/:month?=01/days", &httpTestHandler{})
+	})
+}
+
+func TestMatchFold(t *testing.T) {
+	r := newRouteTree()
+	r.addRoute("/Users/:id/Keys", &httpTestHandler{})
+	r.addRoute("/users/new/keys", &httpTestHandler{})
+
+	h, _, _ := testMatch(r, "/USERS/42/keys")
+	assert.Nil(t, h)
+
+	p := &params_{}
+	fold := &foldState{fix: true}
+	route := r.match("/USERS/AbC/keys", p, fold, 0)
+	assert.NotNil(t, route)
+	assert.Equal(t, route.routePath, "/Users/:id/Keys")
+	// param value untouched
+	assert.Equal(t, p.Get("id"), "AbC")
+	assert.Equal(t, string(fold.path), "Users/AbC/Keys")
+
+	// a failed static branch is restored before trying the param
+	r = newRouteTree()
+	r.addRoute("/users/new/keys", &httpTestHandler{})
+	r.addRoute("/users/:id/list", &httpTestHandler{})
+	p = &params_{}
+	fold = &foldState{fix: true}
+	route = r.match("/USERS/NEW/list", p, fold, 0)
+	assert.NotNil(t, route)
+	assert.Equal(t, route.routePath, "/users/:id/list")
+	assert.Equal(t, p.Get("id"), "NEW")
+	assert.Equal(t, string(fold.path), "users/NEW/list")
+}
+
+func TestEqualFoldASCII(t *testing.T) {
+	assert.True(t, equalFoldASCII("Users", "uSERS"))
+	assert.False(t, equalFoldASCII("Users", "User"))
+	assert.False(t, equalFoldASCII("Ä", "ä"))
+}
@@ -338,10 +338,16 @@ func BenchmarkRoutingGithub3(b *testing.B) {
 			})
 		}(method, url)
 	}
+	p := &params_{}
+	b.ReportAllocs()
+	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		router.roots["GET"].match("/authorizations/testing")
-		router.roots["GET"].match("/repos/vanng822/r2router/collaborators/vanng822")
-		router.roots["GET"].match("/user/keys/testing")
+		router.trees()["GET"].match("/authorizations/testing", p, nil, 0)
+		p.reset()
+		router.trees()["GET"].match("/repos/vanng822/r2router/collaborators/vanng822", p, nil, 0)
+		p.reset()
+		router.trees()["GET"].match("/user/keys/testing", p, nil, 0)
+		p.reset()
 	}
 }
 
@@ -351,8 +357,12 @@ func BenchmarkRouting5(b *testing.B) {
 		w.WriteHeader(http.StatusOK)
 	})
 
+	p := &params_{}
+	b.ReportAllocs()
+	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		router.roots["GET"].match("/test/test/test/test/test")
+		router.trees()["GET"].match("/test/test/test/test/test", p, nil, 0)
+		p.reset()
 	}
 }
 func BenchmarkRouting20(b *testing.B) {
@@ -361,7 +371,176 @@ func BenchmarkRouting20(b *testing.B) {
 		w.WriteHeader(http.StatusOK)
 	})
 
+	p := &params_{}
+	b.ReportAllocs()
+	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		router.roots["GET"].match("/a/b/c/d/e/f/g/h/i/j/k/l/m/n/o/p/q/r/s/t")
+		router.trees()["GET"].match("/a/b/c/d/e/f/g/h/i/j/k/l/m/n/o/p/q/r/s/t", p, nil, 0)
+		p.reset()
 	}
 }
+
+func TestRoutingBacktrack(t *testing.T) {
+	router := NewRouter()
+	routes := append([][]string{}, githubAPI...)
+	// static routes next to the params of the github routes
+	routes = append(routes, []string{"GET", "/users/new"})
+	routes = append(routes, []string{"GET", "/repos/search/code"})
+	routes = append(routes, []string{"GET", "/user/following/suggested/list"})
+	for _, route := range routes {
+		func(method, url string) {
+			router.AddHandler(method, url, func(w http.ResponseWriter, r *http.Request, p Params) {
+				w.Write([]byte(method + ":" + url))
+			})
+		}(route[0], route[1])
+	}
+
+	tests := []struct {
+		method, path, route string
+		params              map[string]string
+	}{
+		{"GET", "/users/new", "/users/new", map[string]string{}},
+		{"GET", "/users/new/events", "/users/:user/events", map[string]string{"user": "new"}},
+		{"GET", "/users/new/following/vanng822", "/users/:user/following/:target_user", map[string]string{"user": "new", "target_user": "vanng822"}},
+		{"GET", "/repos/search/code", "/repos/search/code", map[string]string{}},
+		{"GET", "/repos/search/code/events", "/repos/:owner/:repo/events", map[string]string{"owner": "search", "repo": "code"}},
+		{"GET", "/user/following/suggested", "/user/following/:user", map[string]string{"user": "suggested"}},
+		{"GET", "/user/following/suggested/list", "/user/following/suggested/list", map[string]string{}},
+		{"DELETE", "/user/following/suggested", "/user/following/:user", map[string]string{"user": "suggested"}},
+	}
+	for _, test := range tests {
+		h, p, route := testMatch(router.trees()[test.method], test.path)
+		assert.NotNil(t, h, test.path)
+		assert.Equal(t, route, test.route, test.path)
+		for k, v := range test.params {
+			assert.Equal(t, p.Get(k), v, test.path)
+		}
+		// no params left from failed branches
+		assert.Equal(t, len(p.requestParams), len(test.params), test.path)
+	}
+
+	h, _, _ := testMatch(router.trees()["GET"], "/users/new/unknown")
+	assert.Nil(t, h)
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := &http.Client{}
+	for _, route := range routes {
+		req, err := http.NewRequest(route[0], ts.URL+route[1], nil)
+		assert.Nil(t, err)
+		res, err := client.Do(req)
+		assert.Nil(t, err)
+		content, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		assert.Equal(t, string(content), route[0]+":"+route[1])
+	}
+}
+
+func TestRoutingBacktrackWildcard(t *testing.T) {
+	r := newRouteTree()
+	r.addRoute("/static/css/:name", &httpTestHandler{})
+	r.addRoute(`/static/:version(v\d+)/app.js`, &httpTestHandler{})
+	r.addRoute("/static/*filepath", &httpTestHandler{})
+
+	_, p, route := testMatch(r, "/static/css/main.css")
+	assert.Equal(t, route, "/static/css/:name")
+	assert.Equal(t, p.Get("name"), "main.css")
+
+	_, p, route = testMatch(r, "/static/css/vendor/reset.css")
+	assert.Equal(t, route, "/static/*filepath")
+	assert.Equal(t, p.Get("filepath"), "css/vendor/reset.css")
+	assert.False(t, p.Has("name"))
+
+	_, p, route = testMatch(r, "/static/v2/app.js")
+	assert.Equal(t, route, `/static/:version(v\d+)/app.js`)
+	assert.Equal(t, p.Get("version"), "v2")
+
+	_, p, route = testMatch(r, "/static/v2/vendor.js")
+	assert.Equal(t, route, "/static/*filepath")
+	assert.Equal(t, p.Get("filepath"), "v2/vendor.js")
+	assert.False(t, p.Has("version"))
+}
+
+type benchResponseWriter struct {
+	header http.Header
+}
+
+func (w *benchResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *benchResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *benchResponseWriter) WriteHeader(code int) {}
+
+func benchmarkRouter(b *testing.B, router http.Handler, paths ...string) {
+	w := &benchResponseWriter{header: make(http.Header)}
+	reqs := make([]*http.Request, 0, len(paths))
+	for _, path := range paths {
+		req, _ := http.NewRequest("GET", path, nil)
+		reqs = append(reqs, req)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, req := range reqs {
+			router.ServeHTTP(w, req)
+		}
+	}
+}
+
+func newGithubRouter() *Router {
+	router := NewRouter()
+	for _, route := range githubAPI {
+		router.AddHandler(route[0], route[1], func(w http.ResponseWriter, r *http.Request, p Params) {})
+	}
+	return router
+}
+
+func BenchmarkRouterStatic(b *testing.B) {
+	benchmarkRouter(b, newGithubRouter(), "/user/repos")
+}
+
+func BenchmarkRouterParam(b *testing.B) {
+	benchmarkRouter(b, newGithubRouter(), "/user/keys/testing")
+}
+
+func BenchmarkRouterParams3(b *testing.B) {
+	benchmarkRouter(b, newGithubRouter(), "/repos/vanng822/r2router/collaborators/vanng822")
+}
+
+func BenchmarkRouterGithubAll(b *testing.B) {
+	router := newGithubRouter()
+	paths := make([]string, 0, len(githubAPI))
+	for _, route := range githubAPI {
+		if route[0] == "GET" {
+			paths = append(paths, route[1])
+		}
+	}
+	benchmarkRouter(b, router, paths...)
+}
+
+// BenchmarkRouterPathValues uses a new request each time
+// since path values set on a request are reused
+func BenchmarkRouterPathValues(b *testing.B) {
+	router := newGithubRouter()
+	router.SetPathValues = true
+	w := &benchResponseWriter{header: make(http.Header)}
+	base, _ := http.NewRequest("GET", "/repos/vanng822/r2router/collaborators/vanng822", nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := new(http.Request)
+		*req = *base
+		router.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkRouter20Params(b *testing.B) {
+	router := NewRouter()
+	router.Get("/:a/:b/:c/:d/:e/:f/:g/:h/:i/:j/:k/:l/:m/:n/:o/:p/:q/:r/:s/:t", func(w http.ResponseWriter, r *http.Request, p Params) {})
+	benchmarkRouter(b, router, "/a/b/c/d/e/f/g/h/i/j/k/l/m/n/o/p/q/r/s/t")
+}
@@ -39,7 +39,6 @@ func NewSeeforRouter() *Seefor {
 	c4 := &Seefor{}
 	c4.afters = make([]After, 0)
 	c4.befores = make([]Before, 0)
-	c4.roots = make(map[string]*rootNode)
 	c4.HandleMethodNotAllowed = true
 	return c4
 }
@@ -50,20 +49,25 @@ func (c4 *Seefor) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	started := time.Now()
 	c4.handleBeforeMiddlewares(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		beforeEnd := time.Now()
-		if root, exist := c4.roots[req.Method]; exist {
-			handler, params, route := root.match(req.URL.Path)
-			if handler != nil {
-				if c4.timer != nil {
-					after := time.Now()
-					c4.handleAfterMiddlewares(handler, w, req, params)
-					c4.timer.Get(route).Accumulate(started, beforeEnd, after, time.Now())
-				} else {
-					c4.handleAfterMiddlewares(handler, w, req, params)
-				}
-				return
+		path, ok := c4.routingPath(w, req)
+		if !ok {
+			return
+		}
+		params := acquireParams()
+		if route := c4.lookup(req.Method, path, params, true); route != nil {
+			req = c4.completeParams(req, params)
+			if c4.timer != nil {
+				after := time.Now()
+				c4.handleAfterMiddlewares(route.handler, w, req, params)
+				c4.timer.Get(route.routePath).Accumulate(started, beforeEnd, after, time.Now())
+			} else {
+				c4.handleAfterMiddlewares(route.handler, w, req, params)
 			}
+			releaseParams(params)
+			return
 		}
-		c4.Router.handleMissing(w, req)
+		releaseParams(params)
+		c4.Router.handleMissing(w, req, path)
 	}), w, req)
 }
 
@@ -81,6 +85,80 @@ func (c4 *Seefor) handleAfterMiddlewares(handler Handler, w http.ResponseWriter,
 	handler.ServeHTTP(w, req, params)
 }
 
+// chainHandler is a handler wrapped in route
+// or group middlewares when it was added
+type chainHandler struct {
+	// handler is the one which was added
+	handler Handler
+	chain   Handler
+}
+
+func (h *chainHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, params Params) {
+	h.chain.ServeHTTP(w, req, params)
+}
+
+// chain wraps the handler in the middlewares,
+// the first one runs first
+func chain(handler Handler, middlewares []After) Handler {
+	if len(middlewares) == 0 {
+		return handler
+	}
+	wrapped := handler
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		wrapped = middlewares[i](wrapped)
+	}
+	return &chainHandler{handler, wrapped}
+}
+
+func (c4 *Seefor) Get(path string, handler HandlerFunc, middlewares ...After) *Route {
+	return c4.AddHandler(HTTP_METHOD_GET, path, handler, middlewares...)
+}
+
+func (c4 *Seefor) Head(path string, handler HandlerFunc, middlewares ...After) *Route {
+	return c4.AddHandler(HTTP_METHOD_HEAD, path, handler, middlewares...)
+}
+
+func (c4 *Seefor) Post(path string, handler HandlerFunc, middlewares ...After) *Route {
+	return c4.AddHandler(HTTP_METHOD_POST, path, handler, middlewares...)
+}
+
+func (c4 *Seefor) Put(path string, handler HandlerFunc, middlewares ...After) *Route {
+	return c4.AddHandler(HTTP_METHOD_PUT, path, handler, middlewares...)
+}
+
+func (c4 *Seefor) Delete(path string, handler HandlerFunc, middlewares ...After) *Route {
+	return c4.AddHandler(HTTP_METHOD_DELETE, path, handler, middlewares...)
+}
+
+func (c4 *Seefor) Patch(path string, handler HandlerFunc, middlewares ...After) *Route {
+	return c4.AddHandler(HTTP_METHOD_PATCH, path, handler, middlewares...)
+}
+
+// AddHandler is as Router.AddHandler but takes After middlewares
+// for the route only. They run after the global After middlewares
+func (c4 *Seefor) AddHandler(method, path string, handler HandlerFunc, middlewares ...After) *Route {
+	return c4.mustAdd([]string{method}, path, chain(handler, middlewares))[0]
+}
+
+// Handle is as Router.Handle with After middlewares for the routes
+func (c4 *Seefor) Handle(methods []string, path string, handler HandlerFunc, middlewares ...After) []*Route {
+	return c4.mustAdd(methods, path, chain(handler, middlewares))
+}
+
+// Any is as Router.Any with After middlewares for the route
+func (c4 *Seefor) Any(path string, handler HandlerFunc, middlewares ...After) *Route {
+	return c4.mustAdd([]string{HTTP_METHOD_ANY}, path, chain(handler, middlewares))[0]
+}
+
+// Group is as Router.Group but takes After middlewares for all routes
+// in the group. They run after the global After middlewares
+// and before those given for a route
+func (c4 *Seefor) Group(path string, fn func(r *GroupRouter), middlewares ...After) {
+	gr := NewGroupRouter(&c4.Router, path)
+	gr.afters = middlewares
+	fn(gr)
+}
+
 // Before is for adding middleware for running before routing
 func (c4 *Seefor) Before(middleware ...Before) {
 	c4.befores = append(c4.befores, middleware...)
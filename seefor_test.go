@@ -249,3 +249,159 @@ func TestMiddlewareBefore(t *testing.T) {
 	assert.Equal(t, res.StatusCode, http.StatusOK)
 	assert.Equal(t, string(content), "Hello World")
 }
+
+func TestSeeforTimerOptional(t *testing.T) {
+	router := NewSeeforRouter()
+	router.Get("/reports/:year/:month?", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(p.Get("year")))
+	})
+	timer := router.UseTimer(nil)
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	for _, path := range []string{"/reports/2015", "/reports/2015/05"} {
+		res, err := http.Get(ts.URL + path)
+		assert.Nil(t, err)
+		res.Body.Close()
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+	}
+	// both forms are counted on the declared route
+	assert.Equal(t, len(timer.routes), 1)
+	assert.Equal(t, timer.Get("/reports/:year/:month?").Count, int64(2))
+}
+
+func TestSeeforRedirects(t *testing.T) {
+	router := NewSeeforRouter()
+	router.StrictSlash = true
+	router.RedirectTrailingSlash = true
+	router.CleanPath = true
+	router.RedirectCleanPath = true
+	befores := 0
+	router.Before(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			befores++
+			next.ServeHTTP(w, r)
+		})
+	})
+	router.Get("/users/", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("GET:/users/"))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+	assert.Equal(t, w.Code, http.StatusMovedPermanently)
+	assert.Equal(t, w.Header().Get("Location"), "/users/")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/keys/../users/", nil))
+	assert.Equal(t, w.Code, http.StatusMovedPermanently)
+	assert.Equal(t, w.Header().Get("Location"), "/users/")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/", nil))
+	assert.Equal(t, w.Body.String(), "GET:/users/")
+	// before middlewares see redirected requests too
+	assert.Equal(t, befores, 3)
+}
+
+func TestSeeforEscapedPath(t *testing.T) {
+	router := NewSeeforRouter()
+	router.UseEscapedPath = true
+	router.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte(p.Get("id")))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/domain%5Cvan%2Fng", nil))
+	assert.Equal(t, w.Body.String(), "domain\\van/ng")
+}
+
+func TestSeeforHandleHEAD(t *testing.T) {
+	seefor := NewSeeforRouter()
+	seefor.EnableMethodCompliance()
+	seefor.After(func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request, p Params) {
+			w.Header().Set("X-After", "1")
+			next.ServeHTTP(w, r, p)
+		})
+	})
+	seefor.Get("/users/:id", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("user:" + p.Get("id")))
+	})
+	w := serveHost(seefor, "HEAD", "example.com", "/users/1")
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Header().Get("X-After"), "1")
+
+	// net/http discards the body
+	ts := httptest.NewServer(seefor)
+	defer ts.Close()
+	res, err := http.Head(ts.URL + "/users/1")
+	assert.Nil(t, err)
+	content, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, res.Header.Get("X-After"), "1")
+	assert.Equal(t, len(content), 0)
+
+	w = serveHost(seefor, "POST", "example.com", "/users/1")
+	assert.Equal(t, w.Header().Get("Allow"), "GET, HEAD")
+}
+
+// traceAfter returns a middleware appending name to the "trace" app data
+func traceAfter(name string) After {
+	return Wrap(func(w http.ResponseWriter, r *http.Request, p Params) {
+		trace, _ := p.AppGet("trace").(string)
+		p.AppSet("trace", trace+name+",")
+	})
+}
+
+func TestSeeforRouteMiddleware(t *testing.T) {
+	router := NewSeeforRouter()
+	router.After(traceAfter("global"))
+	handler := func(w http.ResponseWriter, r *http.Request, p Params) {
+		trace, _ := p.AppGet("trace").(string)
+		w.Write([]byte(trace + "handler"))
+	}
+	router.Get("/user/:id", handler, traceAfter("first"), traceAfter("second"))
+	router.Post("/user/:id", handler)
+	router.Group("/admin", func(r *GroupRouter) {
+		r.Get("/", handler)
+		r.Get("/users/:id", handler, traceAfter("route"))
+		r.Any("/any", handler)
+	}, traceAfter("group"), traceAfter("auth"))
+	router.Get("/public", handler)
+
+	assert.Equal(t, serveHost(router, "GET", "", "/user/1").Body.String(), "global,first,second,handler")
+	assert.Equal(t, serveHost(router, "POST", "", "/user/1").Body.String(), "global,handler")
+	assert.Equal(t, serveHost(router, "GET", "", "/admin").Body.String(), "global,group,auth,handler")
+	assert.Equal(t, serveHost(router, "GET", "", "/admin/users/1").Body.String(), "global,group,auth,route,handler")
+	assert.Equal(t, serveHost(router, "DELETE", "", "/admin/any").Body.String(), "global,group,auth,handler")
+	assert.Equal(t, serveHost(router, "GET", "", "/public").Body.String(), "global,handler")
+
+	// the added handler is listed, not the middlewares
+	for _, route := range router.Routes() {
+		assert.Equal(t, route.Handler, "github.com/vanng822/r2router.TestSeeforRouteMiddleware.func1")
+	}
+}
+
+func TestSeeforRouteMiddlewareStop(t *testing.T) {
+	router := NewSeeforRouter()
+	deny := func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request, p Params) {
+			w.WriteHeader(http.StatusForbidden)
+		})
+	}
+	router.Group("/admin", func(r *GroupRouter) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request, p Params) {
+			w.Write([]byte("admin"))
+		})
+	}, deny)
+	router.Get("/", func(w http.ResponseWriter, r *http.Request, p Params) {
+		w.Write([]byte("index"))
+	})
+
+	res := serveHost(router, "GET", "", "/admin")
+	assert.Equal(t, res.Code, http.StatusForbidden)
+	assert.Equal(t, res.Body.String(), "")
+	assert.Equal(t, serveHost(router, "GET", "", "/").Body.String(), "index")
+}
@@ -0,0 +1,152 @@
+package r2router
+
+import (
+	"fmt"
+	"strings"
+)
+
+// segmentPart is either static text or a named param
+// of a path segment such as :name.:ext or v:version
+type segmentPart struct {
+	param        bool
+	text         string // static text or param name
+	constraint   string
+	matcher      Constraint
+	optional     bool
+	defaultValue string
+}
+
+func isParamNameChar(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
+
+// parseSegment splits a path segment into static text and params.
+// A param name is letters, digits and underscore and may be followed
+// by a constraint, :id(\d+) or :oid<uuid>. A segment which starts
+// with a param and has no other param, constraint or dot is a param
+// named by the rest of it up to ?, as before params could be mixed
+// with static text, so :user-id is named user-id. Params must be separated
+// by static text, otherwise there is no way to tell where one ends.
+// A param which is a whole segment can be optional, :lang?, and have
+// a default value used when the segment is absent, :lang?=en
+func parseSegment(token string) ([]segmentPart, error) {
+	parts := make([]segmentPart, 0, 1)
+	for token != "" {
+		i := strings.IndexByte(token, ':')
+		if i == -1 {
+			parts = append(parts, segmentPart{text: token})
+			break
+		}
+		if i > 0 {
+			parts = append(parts, segmentPart{text: token[:i]})
+		} else if len(parts) > 0 {
+			return nil, fmt.Errorf("%w: params must be separated by static text %s", ErrInvalidRoute, token)
+		}
+		token = token[i+1:]
+
+		part := segmentPart{param: true}
+		j := 0
+		if len(parts) == 0 && !strings.ContainsAny(token, ".:(<") {
+			if j = strings.IndexByte(token, '?'); j == -1 {
+				j = len(token)
+			}
+		} else {
+			for j < len(token) && isParamNameChar(token[j]) {
+				j++
+			}
+		}
+		part.text = strings.TrimSpace(token[:j])
+		if part.text == "" {
+			return nil, fmt.Errorf("%w: param name can not be empty", ErrInvalidRoute)
+		}
+		token = token[j:]
+
+		if token != "" && (token[0] == '(' || token[0] == '<') {
+			var err error
+			if part.constraint, token, err = nextConstraint(token); err != nil {
+				return nil, err
+			}
+			if part.matcher, err = compileConstraint(part.constraint); err != nil {
+				return nil, err
+			}
+		}
+
+		if token != "" && token[0] == '?' {
+			if len(parts) > 0 {
+				return nil, fmt.Errorf("%w: optional param must be a whole segment :%s", ErrInvalidRoute, part.text)
+			}
+			part.optional = true
+			if token = token[1:]; token != "" {
+				if token[0] != '=' {
+					return nil, fmt.Errorf("%w: optional param must be a whole segment :%s", ErrInvalidRoute, part.text)
+				}
+				part.defaultValue = token[1:]
+				token = ""
+			}
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// nextConstraint cuts a constraint from the beginning of the token.
+// Parentheses can be nested in a regular expression
+func nextConstraint(token string) (string, string, error) {
+	if token[0] == '<' {
+		if i := strings.IndexByte(token, '>'); i != -1 {
+			return token[:i+1], token[i+1:], nil
+		}
+		return "", "", fmt.Errorf("%w: invalid param constraint %s", ErrInvalidRoute, token)
+	}
+	depth := 0
+	for i := 0; i < len(token); i++ {
+		switch token[i] {
+		case '\\':
+			// escaped, such as \(
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return token[:i+1], token[i+1:], nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("%w: invalid param constraint %s", ErrInvalidRoute, token)
+}
+
+// matchParts matches a path segment against the parts and adds matched params.
+// A param takes as much as possible, ie static text after it matches at its
+// last occurrence, so :name.:ext gives archive.tar and gz for archive.tar.gz.
+// It goes back to an earlier occurrence if the rest of the segment fails
+func matchParts(parts []segmentPart, token string, params *params_) bool {
+	if len(parts) == 0 {
+		return token == ""
+	}
+	part := parts[0]
+	if !part.param {
+		return strings.HasPrefix(token, part.text) && matchParts(parts[1:], token[len(part.text):], params)
+	}
+	if len(parts) == 1 {
+		if token == "" || (part.matcher != nil && !part.matcher(token)) {
+			return false
+		}
+		params.push(part.text, token)
+		return true
+	}
+	mark := params.Len()
+	next := parts[1].text
+	for i := strings.LastIndex(token, next); i > 0; i = strings.LastIndex(token[:i], next) {
+		value := token[:i]
+		if part.matcher != nil && !part.matcher(value) {
+			continue
+		}
+		params.push(part.text, value)
+		if matchParts(parts[1:], token[i:], params) {
+			return true
+		}
+		params.pop(mark)
+	}
+	return false
+}
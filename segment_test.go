@@ -0,0 +1,142 @@
+package r2router
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func mustParseSegment(token string) []segmentPart {
+	parts, err := parseSegment(token)
+	if err != nil {
+		panic(err)
+	}
+	return parts
+}
+
+func TestParseSegment(t *testing.T) {
+	parts := mustParseSegment("users")
+	assert.Equal(t, len(parts), 1)
+	assert.False(t, parts[0].param)
+	assert.Equal(t, parts[0].text, "users")
+
+	parts = mustParseSegment(`:id(\d+)`)
+	assert.Equal(t, len(parts), 1)
+	assert.True(t, parts[0].param)
+	assert.Equal(t, parts[0].text, "id")
+	assert.Equal(t, parts[0].constraint, `(\d+)`)
+	assert.NotNil(t, parts[0].matcher)
+
+	parts = mustParseSegment(":name.:ext")
+	assert.Equal(t, len(parts), 3)
+	assert.Equal(t, parts[0].text, "name")
+	assert.Equal(t, parts[1].text, ".")
+	assert.False(t, parts[1].param)
+	assert.Equal(t, parts[2].text, "ext")
+
+	parts = mustParseSegment("v:version")
+	assert.Equal(t, len(parts), 2)
+	assert.Equal(t, parts[0].text, "v")
+	assert.Equal(t, parts[1].text, "version")
+
+	parts = mustParseSegment(`:year(\d{4})-:month(\d{2}).json`)
+	assert.Equal(t, len(parts), 4)
+	assert.Equal(t, parts[0].constraint, `(\d{4})`)
+	assert.Equal(t, parts[2].constraint, `(\d{2})`)
+	assert.Equal(t, parts[3].text, ".json")
+
+	parts = mustParseSegment(`:name<alpha>@:domain`)
+	assert.Equal(t, len(parts), 3)
+	assert.Equal(t, parts[0].constraint, "<alpha>")
+
+	// any name for a param of a whole segment
+	parts = mustParseSegment(":user-id")
+	assert.Equal(t, len(parts), 1)
+	assert.Equal(t, parts[0].text, "user-id")
+	parts = mustParseSegment(":user-id?=me")
+	assert.Equal(t, len(parts), 1)
+	assert.Equal(t, parts[0].text, "user-id")
+	assert.Equal(t, parts[0].defaultValue, "me")
+	parts = mustParseSegment(":id-:slug")
+	assert.Equal(t, len(parts), 3)
+	assert.Equal(t, parts[0].text, "id")
+	assert.Equal(t, parts[1].text, "-")
+	assert.Equal(t, parts[2].text, "slug")
+}
+
+func TestParseSegmentBroken(t *testing.T) {
+	assert.Panics(t, func() {
+		mustParseSegment(":")
+	})
+	assert.Panics(t, func() {
+		mustParseSegment("v:.json")
+	})
+	assert.Panics(t, func() {
+		mustParseSegment(":a:b")
+	})
+	assert.Panics(t, func() {
+		mustParseSegment(`:id(\d+:b`)
+	})
+	assert.Panics(t, func() {
+		mustParseSegment(":id<uuid")
+	})
+}
+
+func TestParseSegmentError(t *testing.T) {
+	_, err := parseSegment(":a:b")
+	assert.True(t, errors.Is(err, ErrInvalidRoute))
+	_, err = parseSegment("<unknown>")
+	assert.Nil(t, err)
+	_, err = parseSegment(":id<unknown>")
+	assert.True(t, errors.Is(err, ErrInvalidRoute))
+}
+
+func TestMatchParts(t *testing.T) {
+	p := &params_{}
+	assert.True(t, matchParts(mustParseSegment(":name.:ext"), "archive.tar.gz", p))
+	assert.Equal(t, p.Get("name"), "archive.tar")
+	assert.Equal(t, p.Get("ext"), "gz")
+
+	p = &params_{}
+	assert.True(t, matchParts(mustParseSegment(":a-:b-:c"), "x-y-z", p))
+	assert.Equal(t, p.requestParams, []param{{"a", "x"}, {"b", "y"}, {"c", "z"}})
+
+	p = &params_{}
+	assert.True(t, matchParts(mustParseSegment(`:name.:ext(json|xml)`), "report.2015.xml", p))
+	assert.Equal(t, p.Get("name"), "report.2015")
+	assert.Equal(t, p.Get("ext"), "xml")
+
+	p = &params_{}
+	assert.False(t, matchParts(mustParseSegment(`:name.:ext(json|xml)`), "report.csv", p))
+	assert.Equal(t, len(p.requestParams), 0)
+	assert.False(t, matchParts(mustParseSegment(":name.:ext"), "report", p))
+	assert.False(t, matchParts(mustParseSegment(":name.:ext"), ".gitignore", p))
+	assert.False(t, matchParts(mustParseSegment(":name.:ext"), "report.", p))
+	assert.False(t, matchParts(mustParseSegment("v:version"), "w2", p))
+	assert.Equal(t, len(p.requestParams), 0)
+
+	assert.True(t, matchParts(mustParseSegment("@:username"), "@vanng822", p))
+	assert.Equal(t, p.Get("username"), "vanng822")
+}
+
+func TestParseSegmentOptional(t *testing.T) {
+	parts := mustParseSegment(":lang?")
+	assert.Equal(t, len(parts), 1)
+	assert.Equal(t, parts[0].text, "lang")
+	assert.True(t, parts[0].optional)
+	assert.Equal(t, parts[0].defaultValue, "")
+
+	parts = mustParseSegment(`:month(\d{2})?=01`)
+	assert.Equal(t, len(parts), 1)
+	assert.Equal(t, parts[0].text, "month")
+	assert.Equal(t, parts[0].constraint, `(\d{2})`)
+	assert.True(t, parts[0].optional)
+	assert.Equal(t, parts[0].defaultValue, "01")
+
+	assert.Panics(t, func() {
+		mustParseSegment("v:version?")
+	})
+	assert.Panics(t, func() {
+		mustParseSegment(":name?.json")
+	})
+}
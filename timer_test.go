@@ -40,7 +40,7 @@ func TestTimer(t *testing.T) {
 			end := time.Now()
 			timer.Get(name).Accumulate(before, after, end, time.Now())
 			w.Done()
-		}(before, time.Now(), string(i%25))
+		}(before, time.Now(), string(rune(i%25)))
 	}
 	w.Wait()
 	assert.NotNil(t, timer.routes)
@@ -58,7 +58,7 @@ func TestTimerStats(t *testing.T) {
 			end := time.Now()
 			timer.Get(name).Accumulate(before, after, end, time.Now())
 			w.Done()
-		}(before, time.Now(), "r"+string(i%25))
+		}(before, time.Now(), "r"+string(rune(i%25)))
 	}
 	w.Wait()
 	assert.NotNil(t, timer.routes)